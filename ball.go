@@ -0,0 +1,118 @@
+package main
+
+import (
+	"math"
+
+	"michelo851a1203/hex-motion/physics"
+)
+
+// ----------------------------------------------------
+// Spawning balls.
+// ----------------------------------------------------
+
+// Spawning: balls after the first start at random points within
+// spawnRadius of the container center, moving at spawnSpeed in a random
+// direction. spawnAttempts bounds the search for a point inside the walls.
+const (
+	spawnRadius   = 150.0
+	spawnSpeed    = 100.0
+	spawnAttempts = 100
+)
+
+// spawnBalls replaces the balls with n new ones. The first starts at
+// startPoint with the configured launch velocity; the rest are placed by g.rng so a
+// seed reproduces the same layout.
+func (g *Game) spawnBalls(n int) {
+	balls := make([]*Ball, n)
+	for i := range balls {
+		if i == 0 {
+			start, _ := g.startPoint()
+			launch := Vector{X: g.cfg.LaunchSpeed, Y: 0}.Rotate(g.cfg.LaunchAngle * math.Pi / 180)
+			balls[i] = g.newBall(i, start, launch)
+			continue
+		}
+		balls[i] = g.extraBall(i)
+	}
+	g.world.Balls = balls
+	g.colorBalls()
+}
+
+// addBalls adds n more balls, placed like the extra ones spawnBalls
+// creates.
+func (g *Game) addBalls(n int) {
+	for range n {
+		g.world.Balls = append(g.world.Balls, g.extraBall(len(g.world.Balls)))
+	}
+	g.colorBalls()
+}
+
+// extraBall creates ball number i at a random spawn point, moving at
+// spawnSpeed in a random direction.
+func (g *Game) extraBall(i int) *Ball {
+	vel := Vector{X: spawnSpeed, Y: 0}.Rotate(g.rng.Float64() * 2 * math.Pi)
+	return g.newBall(i, g.randomSpawnPoint(), vel)
+}
+
+// newBall creates ball number i with its configured mass.
+func (g *Game) newBall(i int, pos, vel Vector) *Ball {
+	b, err := physics.NewBall(pos, vel, g.cfg.BallMass(i), g.cfg.TrailLength)
+	if err != nil {
+		// Config.Validate rejects non-positive masses.
+		panic(err)
+	}
+	return b
+}
+
+// startPoint returns where the first ball starts: spawnRadius above the
+// container center, or the center itself if the ball wouldn't fit inside
+// the walls there (moved reports which).
+func (g *Game) startPoint() (p Vector, moved bool) {
+	center := g.world.Container.Center()
+	if p := center.Add(Vector{X: 0, Y: -spawnRadius}); g.world.FitsInside(p, 0) {
+		return p, false
+	}
+	return center, true
+}
+
+// randomSpawnPoint returns a random point near the container center where
+// a ball fits inside the walls, or the center itself if none is found.
+func (g *Game) randomSpawnPoint() Vector {
+	center := g.world.Container.Center()
+	for range spawnAttempts {
+		// Uniform over the disk: the square root evens out the density.
+		r := spawnRadius * math.Sqrt(g.rng.Float64())
+		p := center.Add(Vector{X: r, Y: 0}.Rotate(g.rng.Float64() * 2 * math.Pi))
+		if g.world.FitsInside(p, 0) {
+			return p
+		}
+	}
+	return center
+}
+
+// colorBalls assigns each ball a color from the theme's palette, in turn.
+func (g *Game) colorBalls() {
+	for i, b := range g.world.Balls {
+		b.Color = g.theme.Balls[i%len(g.theme.Balls)]
+	}
+}
+
+// BallPosition returns the first ball's position in screen pixels.
+func (g *Game) BallPosition() Vector {
+	return g.world.Balls[0].Pos
+}
+
+// BallVelocity returns the first ball's velocity in px/s.
+func (g *Game) BallVelocity() Vector {
+	return g.world.Balls[0].Vel
+}
+
+// SetBall moves the first ball to pos with velocity vel. Its trail starts
+// over and it counts as back in the container, so scripts and tests can
+// set up a situation and step the simulation from there.
+func (g *Game) SetBall(pos, vel Vector) {
+	b := g.world.Balls[0]
+	b.Pos, b.Vel = pos, vel
+	b.Escaped = false
+	b.Trail.Clear()
+	g.dropSnapshot()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ----------------------------------------------------
+// Camera.
+// ----------------------------------------------------
+
+// The camera zooms by zoomFactor per mouse-wheel notch, between
+// minCameraScale and maxCameraScale.
+const (
+	zoomFactor     = 1.1
+	minCameraScale = 0.25
+	maxCameraScale = 16.0
+)
+
+// updateCamera zooms toward the cursor with the mouse wheel and pans while
+// the middle button is dragged.
+func (g *Game) updateCamera() {
+	cursor := g.input.cursor
+
+	if dy := g.input.wheel; dy != 0 {
+		g.zoomCamera(g.cameraScale*math.Pow(zoomFactor, dy), cursor)
+	}
+
+	if g.input.buttonPressed(ebiten.MouseButtonMiddle) {
+		if !g.input.buttonJustPressed(ebiten.MouseButtonMiddle) {
+			g.cameraOffset = g.cameraOffset.Add(cursor.Sub(g.panFrom))
+		}
+		g.panFrom = cursor
+	}
+}
+
+// zoomCamera sets the camera scale, within its limits, keeping the point
+// at screen position about where it is on screen.
+func (g *Game) zoomCamera(scale float64, about Vector) {
+	scale = math.Max(minCameraScale, math.Min(maxCameraScale, scale))
+	g.cameraOffset = about.Sub(about.Sub(g.cameraOffset).Mul(scale / g.cameraScale))
+	g.cameraScale = scale
+}
+
+// moveCamera centers the view on the world point center, zoomed to scale
+// (within the camera's limits).
+func (g *Game) moveCamera(center Vector, scale float64) {
+	g.cameraScale = math.Max(minCameraScale, math.Min(maxCameraScale, scale))
+	screenCenter := Vector{X: float64(g.screenW) / 2, Y: float64(g.screenH) / 2}
+	g.cameraOffset = screenCenter.Sub(center.Mul(g.cameraScale))
+}
+
+// resetCamera returns to the unzoomed, unpanned view.
+func (g *Game) resetCamera() {
+	g.cameraScale, g.cameraOffset = 1, Vector{}
+}
+
+// cameraGeoM returns the camera's transform from the view to the screen.
+func (g *Game) cameraGeoM() ebiten.GeoM {
+	var m ebiten.GeoM
+	m.Scale(g.cameraScale, g.cameraScale)
+	m.Translate(g.cameraOffset.X, g.cameraOffset.Y)
+	return m
+}
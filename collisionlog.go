@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"slices"
+
+	"michelo851a1203/hex-motion/physics"
+)
+
+// ----------------------------------------------------
+// Collision log.
+// ----------------------------------------------------
+
+// The collision log is a JSONL file with one line per wall collision,
+// for example:
+//
+//	{"frame":84,"time":1.4,"ball":0,"edge":3,"point":{"X":461.2,"Y":470.8},"normal":{"X":-0.49,"Y":-0.87},"velBefore":{"X":98.3,"Y":689.5},"velAfter":{"X":-190.7,"Y":-151.6},"impactSpeed":651.4}
+
+// collisionRecord is one line of the collision log.
+type collisionRecord struct {
+	Frame       int     `json:"frame"` // Update the collision happened in.
+	Time        float64 `json:"time"`  // Simulated seconds since the last reset.
+	Ball        int     `json:"ball"`  // Index of the ball.
+	Edge        int     `json:"edge"`  // Index of the wall.
+	Point       Vector  `json:"point"`
+	Normal      Vector  `json:"normal"`
+	VelBefore   Vector  `json:"velBefore"`
+	VelAfter    Vector  `json:"velAfter"`
+	ImpactSpeed float64 `json:"impactSpeed"`
+}
+
+// collisionLog appends collision records to a file. Writes are buffered;
+// the first error is kept and reported by close.
+type collisionLog struct {
+	file *os.File
+	buf  *bufio.Writer
+	enc  *json.Encoder
+	err  error
+}
+
+// createCollisionLog creates (or truncates) the log file at path.
+func createCollisionLog(path string) (*collisionLog, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(f)
+	return &collisionLog{file: f, buf: buf, enc: json.NewEncoder(buf)}, nil
+}
+
+// record appends collision c, among balls, to the log.
+func (l *collisionLog) record(frame int, time float64, balls []*Ball, c physics.Collision) {
+	if l.err != nil {
+		return
+	}
+	l.err = l.enc.Encode(collisionRecord{
+		Frame:       frame,
+		Time:        time,
+		Ball:        slices.Index(balls, c.Ball),
+		Edge:        c.Edge,
+		Point:       c.Point,
+		Normal:      c.Normal,
+		VelBefore:   c.VelBefore,
+		VelAfter:    c.Ball.Vel,
+		ImpactSpeed: c.ImpactSpeed,
+	})
+}
+
+// close flushes the log and closes the file.
+func (l *collisionLog) close() error {
+	if l.err == nil {
+		l.err = l.buf.Flush()
+	}
+	if err := l.file.Close(); l.err == nil {
+		l.err = err
+	}
+	return l.err
+}
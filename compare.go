@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// ----------------------------------------------------
+// Split-screen comparison.
+// ----------------------------------------------------
+
+// dividerColor is the line between the two halves of a split screen.
+var dividerColor = color.RGBA{128, 128, 128, 255}
+
+// splitScreen runs two games side by side, each simulating on its own and
+// drawn into its half of the window, so two parameter sets can be compared
+// as they play out from the same seed. Both get the same keyboard input;
+// the mouse acts on the half it is over. Only the left game handles the
+// window and file keys (see Game.windowKeys).
+type splitScreen struct {
+	left, right *Game
+	// Offscreen images the games are drawn into, sized to their halves.
+	leftImage, rightImage *ebiten.Image
+}
+
+// compareConfig returns cfg with the flags in args (as for the command
+// line, e.g. "-restitution 0.5 -angular-speed 2") applied on top, for the
+// right half of a split screen.
+func compareConfig(cfg Config, args string) (Config, error) {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	cfg.RegisterFlags(fs)
+	if err := fs.Parse(strings.Fields(args)); err != nil {
+		return cfg, err
+	}
+	if fs.NArg() > 0 {
+		return cfg, errors.New("-compare takes only flags")
+	}
+	return cfg, cfg.Validate()
+}
+
+// newSplitScreen puts left and right side by side. The games must have
+// been created for half the window width each.
+func newSplitScreen(left, right *Game) *splitScreen {
+	right.windowKeys = false
+	right.origin = Vector{X: float64(left.screenW)}
+	return &splitScreen{left: left, right: right}
+}
+
+// Update advances both games. Either one quitting ends the run, after
+// both have had their Update (so both finish their GIF recordings).
+func (s *splitScreen) Update() error {
+	errLeft := s.left.Update()
+	errRight := s.right.Update()
+	if errLeft != nil {
+		return errLeft
+	}
+	return errRight
+}
+
+// Draw draws each game into its half of the screen, with a divider
+// between them.
+func (s *splitScreen) Draw(screen *ebiten.Image) {
+	s.leftImage = drawInto(screen, s.leftImage, s.left)
+	s.rightImage = drawInto(screen, s.rightImage, s.right)
+	x := float32(s.right.origin.X)
+	vector.StrokeLine(screen, x, 0, x, float32(s.right.screenH), 1, dividerColor, false)
+}
+
+// drawInto draws g into img, replacing it with a new image if it doesn't
+// match g's screen size, and copies it onto screen at g's origin.
+func drawInto(screen, img *ebiten.Image, g *Game) *ebiten.Image {
+	if img == nil || img.Bounds().Dx() != g.screenW || img.Bounds().Dy() != g.screenH {
+		if img != nil {
+			img.Deallocate()
+		}
+		img = ebiten.NewImage(g.screenW, g.screenH)
+	}
+	g.Draw(img)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(g.origin.X, g.origin.Y)
+	screen.DrawImage(img, op)
+	return img
+}
+
+// Layout splits the window between the two games, the left one taking
+// the narrower half of an odd width.
+func (s *splitScreen) Layout(outsideWidth, outsideHeight int) (int, int) {
+	half := outsideWidth / 2
+	s.left.Layout(half, outsideHeight)
+	s.right.Layout(outsideWidth-half, outsideHeight)
+	s.right.origin = Vector{X: float64(half)}
+	return outsideWidth, outsideHeight
+}
@@ -0,0 +1,472 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image/color"
+	"math"
+	"slices"
+	"strings"
+)
+
+// ----------------------------------------------------
+// Configuration.
+// ----------------------------------------------------
+
+// Config holds every tunable parameter of the simulation. NewGame builds
+// a Game from it and reset returns to it.
+type Config struct {
+	// Window size in pixels.
+	ScreenWidth  int
+	ScreenHeight int
+	// Fixed resolution the scene is simulated and drawn at, scaled to fit
+	// the window; 0 by 0 follows the window size instead.
+	RenderWidth  int
+	RenderHeight int
+
+	// Balls.
+	Balls           int       // Number of balls.
+	Masses          []float64 // Mass of each ball, first ones first; the rest weigh 1.
+	BallCollisions  bool      // Balls collide with each other.
+	BallRadius      float64   // Drawn radius.
+	CollisionMargin float64   // Added to BallRadius for collisions (may be negative).
+	LaunchSpeed     float64   // First ball's initial speed (px/s).
+	LaunchAngle     float64   // First ball's initial direction, in degrees (0 = right, 90 = down).
+
+	// Container.
+	Container    string    // "polygon" or "circle".
+	HexRadius    float64   // Distance from the center to a vertex (or circle radius).
+	Sides        int       // Number of polygon sides (at least 3).
+	Points       []Vector  // Custom polygon vertices; overrides HexRadius/Sides.
+	AngularSpeed float64   // Initial rotation speed (radians per second).
+	SpinMode     string    // How the rotation speed varies: one of spinModes.
+	SpinPeriod   float64   // Seconds per cycle of a varying spin.
+	Rings        int       // Number of concentric rings nested inside the container.
+	RingSpeeds   []float64 // Rotation speed of each ring, outermost first.
+	OpenEdge     int       // Index of the container wall left open (-1 for none).
+	RingGaps     bool      // Leave wall 0 of every ring open.
+	GatePeriod   float64   // Seconds before each opening moves on to the next wall (0 keeps it fixed).
+	ScreenWalls  []string  // Screen edges balls also bounce off: any of screenSides.
+	NoContainer  bool      // Drop the container's walls, leaving the rings and screen walls.
+
+	// Physics.
+	Gravity       float64 // Gravity strength (pixels per second²).
+	GravityAngle  float64 // Direction gravity pulls, in degrees (90 = down).
+	NoGravity     bool    // Keep gravity off, even against the Space key.
+	Restitution   float64 // Fraction of normal speed kept on a head-on bounce.
+	GrazingFactor float64 // Restitution multiplier for grazing hits.
+	Wind          float64 // Peak acceleration of a sinusoidal breeze (px/s²).
+	Drag          float64 // Air drag rate per second.
+	Friction      float64 // Coulomb friction coefficient against walls.
+	Substeps      int     // Physics sub-steps per fixed step.
+	MaxSpeed      float64 // Ball speed limit in px/s (0 disables it).
+	// Walls with their own restitution and friction, by wall index
+	// (container walls first, then each ring's).
+	Materials map[int]Material
+	// Impact speed (px/s) at which restitution has halved; 0 keeps it
+	// independent of speed.
+	RestitutionFalloff float64
+	// Central attraction toward the container's center, independent of
+	// gravity, and its strength (see wellAccel).
+	GravityWell  bool
+	WellStrength float64
+
+	// Ball heat tint.
+	HeatPerSpeed float64 // Heat added per pixel/second of impact speed.
+	HeatDecay    float64 // Cooling rate per second.
+
+	// Appearance.
+	WallThickness float64
+	WallColor     color.RGBA // Overrides the theme's wall color if set.
+	WallHeat      bool       // Color walls by recent impacts.
+	WallHeatDecay float64    // Wall cooling rate per second.
+	TrailLength   int        // Positions kept in the trail (0 disables it).
+	SpeedTrails   bool       // Color trails by the ball's speed.
+	Shaded        bool       // Shade the ball like a lit sphere.
+	LightAngle    float64    // Direction the light comes from, in degrees.
+	PredictTime   float64    // Seconds of predicted path drawn ahead of each ball (0 disables it).
+	Particles     bool       // Throw sparks off every collision.
+	DrawOrder     string     // Order the balls are drawn in: one of drawOrders.
+	Interpolate   bool       // Blend the drawn scene between physics steps.
+	Grid          bool       // Draw a background grid.
+	Speedometer   bool       // Draw a speed gauge in the corner.
+	GaugeMax      float64    // Speed (px/s) that fills the gauge.
+	GridSpacing   float64    // Distance between grid lines in pixels.
+
+	// Audio.
+	Sound bool // Play a sound on every wall hit.
+
+	// Seed for Game.rng. The physics itself is deterministic; any random
+	// choice (spawn positions, colors, ...) must draw from Game.rng so
+	// the same seed and config replay a run frame for frame. The purely
+	// visual sparks draw from a source of their own.
+	Seed int64
+
+	// Diagnostics.
+	EnergyCheck bool // Warn when a bounce adds energy.
+	FreezeOnHit bool // Pause at every new wall collision.
+	GIFFrames   int  // Maximum frames per GIF recording.
+}
+
+// DefaultConfig returns the standard setup: a 200px hexagon spinning at
+// 0.5 rad/s in an 800x600 window.
+func DefaultConfig() Config {
+	return Config{
+		ScreenWidth:  800,
+		ScreenHeight: 600,
+
+		Balls:          1,
+		BallCollisions: true,
+		LaunchSpeed:    100,
+		BallRadius:     10,
+
+		Container:    "polygon",
+		HexRadius:    200,
+		Sides:        6,
+		AngularSpeed: 0.5,
+		SpinMode:     spinConstant,
+		SpinPeriod:   4,
+		OpenEdge:     -1,
+
+		Gravity:       500,
+		GravityAngle:  90,
+		Restitution:   0.9,
+		GrazingFactor: 1,
+		Drag:          -60 * math.Log(0.99), // Lose 1% of speed per 1/60 s.
+		Friction:      0.2,
+		Substeps:      1,
+		// Just enough for the first ball, spawned 150px above the center
+		// at 100 px/s, to circle it.
+		WellStrength: 1.7e6,
+
+		// A hard hit (~500 px/s) heats the ball roughly halfway.
+		HeatPerSpeed: 0.001,
+		HeatDecay:    1.5,
+
+		WallThickness: 3,
+		WallHeatDecay: 0.5,
+		TrailLength:   60, // One second of history.
+		LightAngle:    225,
+		Particles:     true,
+		DrawOrder:     drawOrderID,
+		Interpolate:   true,
+		GridSpacing:   50,
+		GaugeMax:      1000,
+
+		Seed: 1,
+
+		GIFFrames: 300, // Ten seconds at 30 frames per second.
+	}
+}
+
+// RegisterFlags binds a command-line flag to every field of c, using the
+// current values as defaults.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.IntVar(&c.ScreenWidth, "width", c.ScreenWidth, "window width in pixels")
+	fs.IntVar(&c.ScreenHeight, "height", c.ScreenHeight, "window height in pixels")
+	fs.IntVar(&c.RenderWidth, "render-width", c.RenderWidth, "fixed render width in pixels, scaled to the window (0 follows the window)")
+	fs.IntVar(&c.RenderHeight, "render-height", c.RenderHeight, "fixed render height in pixels, scaled to the window (0 follows the window)")
+
+	fs.IntVar(&c.Balls, "balls", c.Balls, "number of balls")
+	fs.Var((*floatList)(&c.Masses), "masses", `mass of each ball as "a,b,...", first ball first (others weigh 1)`)
+	fs.BoolVar(&c.BallCollisions, "ball-collisions", c.BallCollisions, "let balls collide with each other")
+	fs.Float64Var(&c.BallRadius, "ball-radius", c.BallRadius, "ball radius in pixels")
+	fs.Float64Var(&c.CollisionMargin, "collision-margin", c.CollisionMargin, "extra collision radius beyond the drawn ball (may be negative)")
+	fs.Float64Var(&c.LaunchSpeed, "launch-speed", c.LaunchSpeed, "first ball's initial speed in px/s")
+	fs.Float64Var(&c.LaunchAngle, "launch-angle", c.LaunchAngle, "first ball's initial direction, in degrees (0 = right, 90 = down)")
+
+	fs.StringVar(&c.Container, "container", c.Container, "container shape: polygon or circle")
+	fs.Float64Var(&c.HexRadius, "hex-radius", c.HexRadius, "distance from the center to a polygon vertex, or the circle radius")
+	fs.IntVar(&c.Sides, "sides", c.Sides, "number of polygon sides (minimum 3)")
+	fs.Var((*pointList)(&c.Points), "polygon", `custom polygon vertices as "x,y x,y x,y ..." in screen pixels`)
+	fs.Float64Var(&c.AngularSpeed, "angular-speed", c.AngularSpeed, "polygon rotation speed in rad/s")
+	fs.StringVar(&c.SpinMode, "spin-mode", c.SpinMode, "how the rotation speed varies: constant, sine (swinging between ±-angular-speed) or flip (reversing every period)")
+	fs.Float64Var(&c.SpinPeriod, "spin-period", c.SpinPeriod, "seconds per cycle of a sine spin, or between flips")
+	fs.IntVar(&c.Rings, "rings", c.Rings, "number of concentric rings nested inside the container")
+	fs.IntVar(&c.OpenEdge, "open-edge", c.OpenEdge, "index of a container wall to leave open so balls can escape (-1 for none)")
+	fs.BoolVar(&c.RingGaps, "ring-gaps", c.RingGaps, "leave one wall of every ring open")
+	fs.Float64Var(&c.GatePeriod, "gate-period", c.GatePeriod, "seconds before each opening moves on to the next wall (0 keeps it fixed)")
+	fs.Var((*sideList)(&c.ScreenWalls), "screen-walls", `screen edges balls also bounce off, as "left,right,top,bottom" or a subset`)
+	fs.BoolVar(&c.NoContainer, "no-container", c.NoContainer, "drop the container's walls, leaving the balls to the rings and -screen-walls")
+	fs.Var((*floatList)(&c.RingSpeeds), "ring-speeds", `rotation speed of each ring in rad/s, outermost first, as "a,b,..." (default: alternating directions, each ring faster)`)
+
+	fs.Float64Var(&c.Gravity, "gravity", c.Gravity, "gravity in px/s²")
+	fs.Float64Var(&c.GravityAngle, "gravity-angle", c.GravityAngle, "direction gravity pulls, in degrees (0 = right, 90 = down)")
+	fs.BoolVar(&c.NoGravity, "no-gravity", c.NoGravity, "turn gravity off for good; with -drag 0 -friction 0 -restitution 1 the balls bounce forever")
+	fs.Float64Var(&c.Restitution, "restitution", c.Restitution, "fraction of normal speed kept on a head-on bounce")
+	fs.Float64Var(&c.RestitutionFalloff, "restitution-falloff", c.RestitutionFalloff, "impact speed in px/s at which restitution has halved (0 = independent of speed)")
+	fs.Float64Var(&c.GrazingFactor, "grazing-factor", c.GrazingFactor, "restitution multiplier for grazing hits (1 = same as head-on)")
+	fs.Float64Var(&c.Wind, "wind", c.Wind, "peak acceleration of a gentle horizontal breeze in px/s² (0 = no wind)")
+	fs.BoolVar(&c.GravityWell, "gravity-well", c.GravityWell, "pull the balls toward the container's center with an inverse-square force")
+	fs.Float64Var(&c.WellStrength, "well-strength", c.WellStrength, "gravity well strength: the pull in px/s² at distance d is this over d²")
+	fs.Float64Var(&c.Drag, "drag", c.Drag, "air drag rate per second")
+	fs.Float64Var(&c.Friction, "friction", c.Friction, "wall friction coefficient")
+	fs.Var((*materialList)(&c.Materials), "materials", `per-wall materials as "wall:restitution,friction ..." (other walls use -restitution and -friction)`)
+	fs.Float64Var(&c.MaxSpeed, "max-speed", c.MaxSpeed, "ball speed limit in px/s, keeping direction (0 disables)")
+	fs.IntVar(&c.Substeps, "substeps", c.Substeps, "physics sub-steps per frame; raise for fast-spinning containers")
+
+	fs.Float64Var(&c.HeatPerSpeed, "heat-per-speed", c.HeatPerSpeed, "ball heat added per px/s of impact speed")
+	fs.Float64Var(&c.HeatDecay, "heat-decay", c.HeatDecay, "ball heat cooling rate per second")
+
+	fs.Float64Var(&c.WallThickness, "wall-thickness", c.WallThickness, "wall line thickness in pixels")
+	fs.Var((*hexColor)(&c.WallColor), "wall-color", "wall color as #rrggbb (default: the theme's)")
+	fs.BoolVar(&c.WallHeat, "wall-heat", c.WallHeat, "color each wall by how recently and hard it was hit")
+	fs.Float64Var(&c.WallHeatDecay, "wall-heat-decay", c.WallHeatDecay, "wall heat cooling rate per second")
+	fs.IntVar(&c.TrailLength, "trail", c.TrailLength, "number of recent positions drawn as a trail (0 disables)")
+	fs.BoolVar(&c.SpeedTrails, "speed-trails", c.SpeedTrails, "color trails from blue (slow) to red (fast) instead of in the ball's color")
+	fs.BoolVar(&c.Shaded, "shaded", c.Shaded, "shade the ball like a lit sphere")
+	fs.Float64Var(&c.LightAngle, "light-angle", c.LightAngle, "direction the light comes from, in degrees (0 = right, 90 = down)")
+	fs.BoolVar(&c.Particles, "particles", c.Particles, "throw sparks off every collision")
+	fs.StringVar(&c.DrawOrder, "draw-order", c.DrawOrder, "order the balls are drawn in: id, size (heaviest behind) or speed (slowest behind)")
+	fs.BoolVar(&c.Interpolate, "interpolate", c.Interpolate, "blend the drawn scene between physics steps for smooth motion")
+	fs.BoolVar(&c.Grid, "grid", c.Grid, "draw a faint background grid")
+	fs.BoolVar(&c.Speedometer, "speedometer", c.Speedometer, "draw a gauge of the first ball's speed in the corner")
+	fs.Float64Var(&c.GaugeMax, "gauge-max", c.GaugeMax, "speed in px/s that fills the speedometer")
+	fs.Float64Var(&c.GridSpacing, "grid-spacing", c.GridSpacing, "distance between grid lines in pixels")
+	fs.Float64Var(&c.PredictTime, "predict", c.PredictTime, "seconds of predicted path to draw ahead of each ball, until its first collision (0 disables)")
+
+	fs.BoolVar(&c.Sound, "sound", c.Sound, "play a bounce sound on wall hits, louder for harder hits")
+
+	fs.Int64Var(&c.Seed, "seed", c.Seed, "random seed; the same seed and flags reproduce a run")
+
+	fs.BoolVar(&c.EnergyCheck, "energy-check", c.EnergyCheck, "warn when a collision increases the ball's energy")
+	fs.BoolVar(&c.FreezeOnHit, "freeze-on-hit", c.FreezeOnHit, "pause at the first wall collision, and again at the next one after resuming (Shift+P turns it off)")
+	fs.IntVar(&c.GIFFrames, "gif-frames", c.GIFFrames, "maximum number of frames in a GIF recording")
+}
+
+// Validate reports the first setting that can't produce a working
+// simulation.
+func (c Config) Validate() error {
+	switch {
+	case c.ScreenWidth <= 0 || c.ScreenHeight <= 0:
+		return errors.New("window size must be positive")
+	case c.RenderWidth < 0 || c.RenderHeight < 0 || (c.RenderWidth == 0) != (c.RenderHeight == 0):
+		return errors.New("render size must be both positive, or both 0 to follow the window")
+	case c.Balls < 1:
+		return errors.New("need at least one ball")
+	case slices.ContainsFunc(c.Masses, func(m float64) bool { return m <= 0 }):
+		return errors.New("ball masses must be positive")
+	case c.BallRadius <= 0:
+		return errors.New("ball radius must be positive")
+	case c.LaunchSpeed < 0:
+		return errors.New("launch speed can't be negative")
+	case c.Container != "polygon" && c.Container != "circle":
+		return errors.New("container must be polygon or circle")
+	case c.HexRadius <= 0:
+		return errors.New("hexagon radius must be positive")
+	case c.Sides < 3:
+		return errors.New("polygon needs at least 3 sides")
+	case len(c.Points) > 0 && len(c.Points) < 3:
+		return errors.New("custom polygon needs at least 3 points")
+	case !validRestitution(c.Restitution):
+		return errors.New("restitution must be between 0 and 1")
+	case !validMaterials(c.Materials):
+		return errors.New("wall materials need a non-negative index, a restitution between 0 and 1 and a non-negative friction")
+	case c.RestitutionFalloff < 0:
+		return errors.New("restitution falloff can't be negative")
+	case c.WellStrength < 0:
+		return errors.New("gravity well strength can't be negative")
+	case c.GaugeMax <= 0:
+		return errors.New("speedometer maximum must be positive")
+	case c.Drag < 0:
+		return errors.New("drag can't be negative")
+	case c.MaxSpeed < 0:
+		return errors.New("speed limit can't be negative")
+	case c.Substeps < 1:
+		return errors.New("need at least one physics sub-step")
+	case !slices.Contains(drawOrders, c.DrawOrder):
+		return errors.New("draw order must be id, size or speed")
+	case !slices.Contains(spinModes, c.SpinMode):
+		return errors.New("spin mode must be constant, sine or flip")
+	case c.SpinMode != spinConstant && c.SpinPeriod <= 0:
+		return errors.New("spin period must be positive")
+	case c.Rings < 0:
+		return errors.New("ring count can't be negative")
+	case len(c.RingSpeeds) > c.Rings:
+		return errors.New("more ring speeds than rings")
+	case slices.ContainsFunc(c.ScreenWalls, func(s string) bool { return !slices.Contains(screenSides, s) }):
+		return errors.New("screen walls must be left, right, top or bottom")
+	case c.NoContainer && len(c.ScreenWalls) == 0:
+		return errors.New("without the container, some screen walls are needed to keep the balls in")
+	case c.OpenEdge >= 0 && c.Container == "circle" && len(c.Points) == 0:
+		return errors.New("a circle has no edge to open")
+	case c.OpenEdge < -1 || c.OpenEdge >= c.polygonSides():
+		return errors.New("open edge must be -1 or the index of one of the container's walls")
+	case c.GatePeriod < 0:
+		return errors.New("gate period can't be negative")
+	case c.GridSpacing <= 0:
+		return errors.New("grid spacing must be positive")
+	case c.GIFFrames < 1:
+		return errors.New("GIF frame limit must be at least 1")
+	}
+	return nil
+}
+
+// RenderSize returns the size the scene starts out at: the fixed render
+// size if set, the window size otherwise.
+func (c Config) RenderSize() (int, int) {
+	if c.RenderWidth > 0 {
+		return c.RenderWidth, c.RenderHeight
+	}
+	return c.ScreenWidth, c.ScreenHeight
+}
+
+// polygonSides returns how many walls a polygon container has: one per
+// custom point, or Sides.
+func (c Config) polygonSides() int {
+	if len(c.Points) > 0 {
+		return len(c.Points)
+	}
+	return c.Sides
+}
+
+// BallMass returns the configured mass of ball number i.
+func (c Config) BallMass(i int) float64 {
+	if i < len(c.Masses) {
+		return c.Masses[i]
+	}
+	return 1
+}
+
+// hexColor adapts a color.RGBA to flag.Value using the #rrggbb notation.
+type hexColor color.RGBA
+
+func (h *hexColor) String() string {
+	return formatHexColor(color.RGBA(*h))
+}
+
+func (h *hexColor) Set(s string) error {
+	c, err := parseHexColor(s)
+	if err != nil {
+		return err
+	}
+	*h = hexColor(c)
+	return nil
+}
+
+// pointList adapts a list of vertices to flag.Value using the notation
+// "x,y x,y ...".
+type pointList []Vector
+
+func (l *pointList) String() string {
+	parts := make([]string, len(*l))
+	for i, p := range *l {
+		parts[i] = fmt.Sprintf("%g,%g", p.X, p.Y)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (l *pointList) Set(s string) error {
+	var points []Vector
+	for _, field := range strings.Fields(s) {
+		var p Vector
+		if _, err := fmt.Sscanf(field, "%g,%g", &p.X, &p.Y); err != nil {
+			return fmt.Errorf("invalid point %q: want x,y", field)
+		}
+		points = append(points, p)
+	}
+	*l = points
+	return nil
+}
+
+// floatList adapts a list of numbers to flag.Value using the notation
+// "a,b,...".
+type floatList []float64
+
+func (l *floatList) String() string {
+	parts := make([]string, len(*l))
+	for i, v := range *l {
+		parts[i] = fmt.Sprintf("%g", v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (l *floatList) Set(s string) error {
+	var values []float64
+	for _, field := range strings.Split(s, ",") {
+		var v float64
+		if _, err := fmt.Sscanf(strings.TrimSpace(field), "%g", &v); err != nil {
+			return fmt.Errorf("invalid number %q", field)
+		}
+		values = append(values, v)
+	}
+	*l = values
+	return nil
+}
+
+// screenSides names the screen edges -screen-walls accepts.
+var screenSides = []string{"left", "right", "top", "bottom"}
+
+// sideList adapts a list of screen edges to flag.Value using the notation
+// "left,right,...".
+type sideList []string
+
+func (l *sideList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *sideList) Set(s string) error {
+	var sides []string
+	for _, side := range strings.Split(s, ",") {
+		side = strings.TrimSpace(side)
+		if side == "" {
+			continue
+		}
+		if !slices.Contains(screenSides, side) {
+			return fmt.Errorf("invalid screen edge %q: want one of %s", side, strings.Join(screenSides, ", "))
+		}
+		sides = append(sides, side)
+	}
+	*l = sides
+	return nil
+}
+
+// validRestitution reports whether r is a restitution that doesn't add
+// energy: from 0 (dead) to 1 (perfectly bouncy).
+func validRestitution(r float64) bool {
+	return r >= 0 && r <= 1
+}
+
+// validMaterials reports whether every wall material has a usable index
+// and coefficients.
+func validMaterials(materials map[int]Material) bool {
+	for wall, mat := range materials {
+		if wall < 0 || !validRestitution(mat.Restitution) || mat.Friction < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// materialList adapts per-wall materials to flag.Value using the notation
+// "wall:restitution,friction ...".
+type materialList map[int]Material
+
+func (l *materialList) String() string {
+	walls := make([]int, 0, len(*l))
+	for wall := range *l {
+		walls = append(walls, wall)
+	}
+	slices.Sort(walls)
+	parts := make([]string, len(walls))
+	for i, wall := range walls {
+		mat := (*l)[wall]
+		parts[i] = fmt.Sprintf("%d:%g,%g", wall, mat.Restitution, mat.Friction)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (l *materialList) Set(s string) error {
+	materials := map[int]Material{}
+	for _, field := range strings.Fields(s) {
+		var wall int
+		var mat Material
+		if _, err := fmt.Sscanf(field, "%d:%g,%g", &wall, &mat.Restitution, &mat.Friction); err != nil {
+			return fmt.Errorf("invalid material %q: want wall:restitution,friction", field)
+		}
+		materials[wall] = mat
+	}
+	*l = materials
+	return nil
+}
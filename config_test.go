@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"image/color"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestDefaultConfigIsValid(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+	}{
+		{"no balls", func(c *Config) { c.Balls = 0 }},
+		{"zero mass", func(c *Config) { c.Masses = []float64{1, 0} }},
+		{"zero radius", func(c *Config) { c.BallRadius = 0 }},
+		{"two-sided polygon", func(c *Config) { c.Sides = 2 }},
+		{"two-point polygon", func(c *Config) { c.Points = []Vector{{X: 0, Y: 0}, {X: 1, Y: 1}} }},
+		{"unknown container", func(c *Config) { c.Container = "square" }},
+		{"negative drag", func(c *Config) { c.Drag = -1 }},
+		{"half a render size", func(c *Config) { c.RenderWidth = 640 }},
+		{"unknown spin mode", func(c *Config) { c.SpinMode = "wobble" }},
+		{"unknown draw order", func(c *Config) { c.DrawOrder = "color" }},
+		{"ring speeds without rings", func(c *Config) { c.RingSpeeds = []float64{1} }},
+		{"open edge on a circle", func(c *Config) { c.Container, c.OpenEdge = "circle", 0 }},
+		{"open edge past the last wall", func(c *Config) { c.OpenEdge = 6 }},
+		{"open edge past a custom polygon's walls", func(c *Config) { c.Points = []Vector{{X: 0, Y: 0}, {X: 9, Y: 0}, {X: 0, Y: 9}}; c.OpenEdge = 3 }},
+		{"negative open edge", func(c *Config) { c.OpenEdge = -2 }},
+		{"bouncier than elastic material", func(c *Config) { c.Materials = map[int]Material{1: {Restitution: 1.2}} }},
+		{"negative material friction", func(c *Config) { c.Materials = map[int]Material{1: {Restitution: 0.5, Friction: -1}} }},
+		{"no container or screen walls", func(c *Config) { c.NoContainer = true }},
+		{"no sub-steps", func(c *Config) { c.Substeps = 0 }},
+		{"restitution above 1", func(c *Config) { c.Restitution = 1.2 }},
+		{"negative restitution", func(c *Config) { c.Restitution = -0.5 }},
+	}
+	for _, tt := range tests {
+		c := DefaultConfig()
+		tt.modify(&c)
+		if err := c.Validate(); err == nil {
+			t.Errorf("%s: Validate accepted it", tt.name)
+		}
+	}
+}
+
+// parseFlags applies args to the default config as the command line
+// would.
+func parseFlags(args ...string) (Config, error) {
+	c := DefaultConfig()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	c.RegisterFlags(fs)
+	err := fs.Parse(args)
+	return c, err
+}
+
+func TestRegisterFlags(t *testing.T) {
+	c, err := parseFlags("-balls", "3", "-masses", "2, 0.5", "-gravity", "250",
+		"-polygon", "0,0 100,0 0,100", "-screen-walls", "left,bottom", "-no-container",
+		"-materials", "2:0.5,0.1", "-wall-color", "#ff8000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Balls != 3 || c.Gravity != 250 || !c.NoContainer {
+		t.Errorf("balls %d, gravity %v, no container %v; want 3, 250, true", c.Balls, c.Gravity, c.NoContainer)
+	}
+	if !slices.Equal(c.Masses, []float64{2, 0.5}) || c.BallMass(1) != 0.5 || c.BallMass(2) != 1 {
+		t.Errorf("masses %v give ball masses %v, %v, %v", c.Masses, c.BallMass(0), c.BallMass(1), c.BallMass(2))
+	}
+	if want := []Vector{{X: 0, Y: 0}, {X: 100, Y: 0}, {X: 0, Y: 100}}; !slices.Equal(c.Points, want) {
+		t.Errorf("points %v, want %v", c.Points, want)
+	}
+	if !slices.Equal(c.ScreenWalls, []string{"left", "bottom"}) {
+		t.Errorf("screen walls %v, want [left bottom]", c.ScreenWalls)
+	}
+	if m, ok := c.Materials[2]; !ok || m != (Material{Restitution: 0.5, Friction: 0.1}) || len(c.Materials) != 1 {
+		t.Errorf("materials %v, want wall 2 at 0.5, 0.1", c.Materials)
+	}
+	if want := (color.RGBA{255, 128, 0, 255}); c.WallColor != want {
+		t.Errorf("wall color %v, want %v", c.WallColor, want)
+	}
+	if err := c.Validate(); err != nil {
+		t.Errorf("parsed config doesn't validate: %v", err)
+	}
+	// Flags left alone keep their defaults.
+	if d := DefaultConfig(); c.Restitution != d.Restitution || c.Sides != d.Sides {
+		t.Errorf("restitution %v, sides %d; want the defaults %v, %d", c.Restitution, c.Sides, d.Restitution, d.Sides)
+	}
+}
+
+func TestRegisterFlagsRejects(t *testing.T) {
+	for _, args := range [][]string{
+		{"-masses", "1,heavy"},
+		{"-polygon", "0,0 1"},
+		{"-materials", "2:bouncy"},
+		{"-wall-color", "orange"},
+		{"-screen-walls", "left,ceiling"},
+		{"-balls", "many"},
+	} {
+		if _, err := parseFlags(args...); err == nil {
+			t.Errorf("%q parsed without error", args)
+		}
+	}
+}
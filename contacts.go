@@ -0,0 +1,67 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"michelo851a1203/hex-motion/physics"
+)
+
+// ----------------------------------------------------
+// Contact markers.
+// ----------------------------------------------------
+
+// With the debug HUD on, every resolved collision leaves a marker: a dot
+// at the contact point and a contactNormalLength-pixel arrow along the
+// wall normal, fading out over contactLife seconds of simulated time.
+const (
+	maxContacts         = 64 // Cap on live markers; the oldest are dropped.
+	contactLife         = 0.5
+	contactNormalLength = 25.0
+	contactRadius       = float32(3)
+)
+
+var contactColor = color.RGBA{80, 255, 120, 255}
+
+// contactMarker is where a collision touched the wall and the normal the
+// response used.
+type contactMarker struct {
+	point, normal Vector
+	age           float64 // Seconds since the collision.
+}
+
+// markContact records collision c for drawing, while the debug HUD is on.
+func (g *Game) markContact(c physics.Collision) {
+	if !g.showHUD {
+		return
+	}
+	if len(g.contacts) >= maxContacts {
+		g.contacts = append(g.contacts[:0], g.contacts[1:]...)
+	}
+	g.contacts = append(g.contacts, contactMarker{point: c.Point, normal: c.Normal})
+}
+
+// updateContacts ages the markers and drops the ones that have faded.
+func (g *Game) updateContacts(dt float64) {
+	live := g.contacts[:0]
+	for _, c := range g.contacts {
+		c.age += dt
+		if c.age < contactLife {
+			live = append(live, c)
+		}
+	}
+	g.contacts = live
+}
+
+// drawContacts draws the markers, fading out as they age.
+func (g *Game) drawContacts(screen *ebiten.Image, view ebiten.GeoM) {
+	for _, c := range g.contacts {
+		alpha := 1 - c.age/contactLife
+		clr := color.NRGBA{contactColor.R, contactColor.G, contactColor.B, uint8(255 * alpha)}
+		x, y := view.Apply(c.point.X, c.point.Y)
+		vector.DrawFilledCircle(screen, float32(x), float32(y), contactRadius, clr, true)
+		drawArrow(screen, view, c.point, c.point.Add(c.normal.Mul(contactNormalLength)), clr)
+	}
+}
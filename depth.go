@@ -0,0 +1,39 @@
+package main
+
+import "sort"
+
+// ----------------------------------------------------
+// Ball drawing order.
+// ----------------------------------------------------
+
+// Draw orders: balls are drawn in the order the world holds them, or with
+// the heaviest (the biggest) or the slowest drawn first, so they sit
+// behind the others for a sense of depth.
+const (
+	drawOrderID    = "id"
+	drawOrderSize  = "size"
+	drawOrderSpeed = "speed"
+)
+
+// drawOrders lists the accepted -draw-order values.
+var drawOrders = []string{drawOrderID, drawOrderSize, drawOrderSpeed}
+
+// drawOrder returns the indices of the balls in the order Draw draws
+// them. The sort is stable, so equal balls keep their id order, and works
+// on a copy of the indices: the world's ball order, which the physics
+// iterates in, is left alone.
+func (g *Game) drawOrder() []int {
+	balls := g.world.Balls
+	order := make([]int, len(balls))
+	for i := range order {
+		order[i] = i
+	}
+	switch g.cfg.DrawOrder {
+	case drawOrderSize:
+		// Balls share their radius, so mass stands in for size.
+		sort.SliceStable(order, func(i, j int) bool { return balls[order[i]].Mass > balls[order[j]].Mass })
+	case drawOrderSpeed:
+		sort.SliceStable(order, func(i, j int) bool { return balls[order[i]].Vel.Len() < balls[order[j]].Vel.Len() })
+	}
+	return order
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestDrawOrder checks that each draw order sorts by its key, keeps ties
+// in id order, and leaves the world's balls as they were.
+func TestDrawOrder(t *testing.T) {
+	tests := []struct {
+		order string
+		want  []int
+	}{
+		{drawOrderID, []int{0, 1, 2, 3}},
+		{drawOrderSize, []int{1, 3, 2, 0}},  // Masses 1, 3, 2, 3.
+		{drawOrderSpeed, []int{2, 0, 3, 1}}, // Speeds 20, 40, 10, 20.
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		cfg.Balls, cfg.Masses, cfg.DrawOrder = 4, []float64{1, 3, 2, 3}, tt.order
+		g, err := NewGame(cfg, themes[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, speed := range []float64{20, 40, 10, 20} {
+			g.world.Balls[i].Vel = Vector{X: 0, Y: speed}
+		}
+		balls := slices.Clone(g.world.Balls)
+
+		if got := g.drawOrder(); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: drawn in order %v, want %v", tt.order, got, tt.want)
+		}
+		if !slices.Equal(g.world.Balls, balls) {
+			t.Errorf("%s: sorting the drawing reordered the world's balls", tt.order)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import "math"
+
+// ----------------------------------------------------
+// Open walls and escaping balls.
+// ----------------------------------------------------
+
+// openGates opens the configured walls, each moved on by shift walls
+// around its outline.
+func (g *Game) openGates(shift int) {
+	w := g.world
+	w.OpenWalls = map[int]bool{}
+	if g.cfg.OpenEdge >= 0 {
+		n := len(w.Container.Edges(0))
+		w.OpenWalls[(g.cfg.OpenEdge+shift)%n] = true
+	}
+	if g.cfg.RingGaps {
+		for k, r := range w.Rings {
+			if n := len(r.Shape.Edges(0)); n > 0 {
+				w.OpenWalls[w.RingWalls(k)+shift%n] = true
+			}
+		}
+	}
+	g.gateShift = shift
+}
+
+// updateGates moves the openings on once every gate period.
+func (g *Game) updateGates() {
+	if g.cfg.GatePeriod <= 0 {
+		return
+	}
+	if shift := int(g.simTime / g.cfg.GatePeriod); shift != g.gateShift {
+		g.openGates(shift)
+	}
+}
+
+// onExit counts a ball escaping through an open wall.
+func (g *Game) onExit(b *Ball, wall int) {
+	g.exits++
+	if g.OnExit != nil {
+		g.OnExit(b, wall)
+	}
+}
+
+// respawnEscaped puts escaped balls that have left the screen back in the
+// container at a random spot.
+func (g *Game) respawnEscaped() {
+	margin := g.world.BallRadius
+	for _, b := range g.world.Balls {
+		if !b.Escaped || g.onScreen(b.Pos, margin) {
+			continue
+		}
+		b.Pos = g.randomSpawnPoint()
+		b.Vel = Vector{X: spawnSpeed, Y: 0}.Rotate(g.rng.Float64() * 2 * math.Pi)
+		b.Escaped = false
+		b.Trail.Clear()
+		g.dropSnapshot()
+	}
+}
+
+// onScreen reports whether p is within margin pixels of the screen, in
+// world coordinates.
+func (g *Game) onScreen(p Vector, margin float64) bool {
+	return p.X > -margin && p.Y > -margin &&
+		p.X < float64(g.screenW)+margin && p.Y < float64(g.screenH)+margin
+}
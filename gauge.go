@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// ----------------------------------------------------
+// Speedometer.
+// ----------------------------------------------------
+
+// The speedometer is a horizontal bar in the bottom-right corner, filling
+// up with the first ball's speed up to the gauge maximum and shading from
+// cool to hot on the way.
+const (
+	gaugeWidth  = 160
+	gaugeHeight = 12
+	gaugeMargin = 16
+)
+
+// drawSpeedometer draws the speed gauge onto screen.
+func (g *Game) drawSpeedometer(screen *ebiten.Image) {
+	speed := g.world.Balls[0].Vel.Len()
+	frac := math.Min(1, speed/g.gaugeMax)
+	x := float32(g.screenW - gaugeMargin - gaugeWidth)
+	y := float32(g.screenH - gaugeMargin - gaugeHeight)
+
+	clr := lerpColor(wallCoolColor, wallHotColor, frac)
+	vector.DrawFilledRect(screen, x, y, gaugeWidth, gaugeHeight, g.theme.Grid, false)
+	vector.DrawFilledRect(screen, x, y, gaugeWidth*float32(frac), gaugeHeight, clr, false)
+	vector.StrokeRect(screen, x, y, gaugeWidth, gaugeHeight, 1, g.wallColor, false)
+	// Debug text is 16 pixels high; the label sits just above the bar.
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%.0f / %.0f px/s", speed, g.gaugeMax), int(x), int(y)-16)
+}
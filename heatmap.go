@@ -0,0 +1,96 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ----------------------------------------------------
+// Position heatmap.
+// ----------------------------------------------------
+
+const (
+	heatmapCell  = 10.0 // Cell size in world pixels.
+	heatmapAlpha = 0.6  // Opacity of the hottest cell.
+)
+
+// heatmap is a 2D histogram of ball positions over the screen area,
+// counting one sample per ball per physics step.
+type heatmap struct {
+	cols, rows int
+	counts     []int
+	max        int
+	image      *ebiten.Image // Rendered counts, one pixel per cell.
+	dirty      bool          // Counts changed since image was rendered.
+}
+
+// newHeatmap returns an empty heatmap covering a w×h screen.
+func newHeatmap(w, h int) *heatmap {
+	cols := int(math.Ceil(float64(w) / heatmapCell))
+	rows := int(math.Ceil(float64(h) / heatmapCell))
+	return &heatmap{cols: cols, rows: rows, counts: make([]int, cols*rows)}
+}
+
+// add counts a sample at p, ignoring points off the covered area.
+func (m *heatmap) add(p Vector) {
+	col, row := int(math.Floor(p.X/heatmapCell)), int(math.Floor(p.Y/heatmapCell))
+	if col < 0 || col >= m.cols || row < 0 || row >= m.rows {
+		return
+	}
+	i := row*m.cols + col
+	m.counts[i]++
+	m.max = max(m.max, m.counts[i])
+	m.dirty = true
+}
+
+// clear drops every sample.
+func (m *heatmap) clear() {
+	clear(m.counts)
+	m.max = 0
+	m.dirty = true
+}
+
+// draw overlays the heatmap, each cell colored from cool to hot by its
+// count relative to the busiest cell. Counts are scaled logarithmically so
+// that rarely visited cells still show up next to a resting spot.
+func (m *heatmap) draw(screen *ebiten.Image, view ebiten.GeoM) {
+	if m.max == 0 {
+		return
+	}
+	if m.image == nil {
+		m.image = ebiten.NewImage(m.cols, m.rows)
+	}
+	if m.dirty {
+		pix := make([]byte, 4*len(m.counts))
+		for i, n := range m.counts {
+			if n == 0 {
+				continue
+			}
+			t := math.Log1p(float64(n)) / math.Log1p(float64(m.max))
+			c := lerpColor(wallCoolColor, wallHotColor, t)
+			// WritePixels takes premultiplied alpha.
+			a := heatmapAlpha * t
+			pix[4*i+0] = uint8(float64(c.R) * a)
+			pix[4*i+1] = uint8(float64(c.G) * a)
+			pix[4*i+2] = uint8(float64(c.B) * a)
+			pix[4*i+3] = uint8(255 * a)
+		}
+		m.image.WritePixels(pix)
+		m.dirty = false
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(heatmapCell, heatmapCell)
+	op.GeoM.Concat(view)
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(m.image, op)
+}
+
+// updateHeatmap samples every ball still in play.
+func (g *Game) updateHeatmap() {
+	for _, b := range g.world.Balls {
+		if !b.Escaped {
+			g.heatmap.add(b.Pos)
+		}
+	}
+}
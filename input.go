@@ -0,0 +1,103 @@
+package main
+
+import (
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// ----------------------------------------------------
+// Input state.
+// ----------------------------------------------------
+
+// trackedButtons are the mouse buttons the controls use.
+var trackedButtons = []ebiten.MouseButton{ebiten.MouseButtonLeft, ebiten.MouseButtonRight, ebiten.MouseButtonMiddle}
+
+// inputState is what the keyboard and mouse look like during one Update.
+// Every control reads its input from here rather than from Ebiten, so a
+// replay can feed recorded input through the same code.
+type inputState struct {
+	keys    map[ebiten.Key]bool
+	buttons map[ebiten.MouseButton]bool
+	cursor  Vector  // Cursor position in screen pixels.
+	wheel   float64 // Vertical mouse-wheel movement this Update.
+
+	// The previous Update's keys and buttons, for the just-pressed checks.
+	prevKeys    map[ebiten.Key]bool
+	prevButtons map[ebiten.MouseButton]bool
+}
+
+// liveInput reads the current keyboard and mouse state from Ebiten.
+func liveInput() inputState {
+	in := inputState{keys: map[ebiten.Key]bool{}, buttons: map[ebiten.MouseButton]bool{}}
+	for _, k := range inpututil.AppendPressedKeys(nil) {
+		in.keys[k] = true
+	}
+	for _, b := range trackedButtons {
+		if ebiten.IsMouseButtonPressed(b) {
+			in.buttons[b] = true
+		}
+	}
+	x, y := ebiten.CursorPosition()
+	in.cursor = Vector{X: float64(x), Y: float64(y)}
+	_, in.wheel = ebiten.Wheel()
+	return in
+}
+
+// keyPressed reports whether key k is held down. KeyShift and KeyControl
+// match either side's key.
+func (in *inputState) keyPressed(k ebiten.Key) bool {
+	switch k {
+	case ebiten.KeyShift:
+		return in.keys[ebiten.KeyShiftLeft] || in.keys[ebiten.KeyShiftRight]
+	case ebiten.KeyControl:
+		return in.keys[ebiten.KeyControlLeft] || in.keys[ebiten.KeyControlRight]
+	}
+	return in.keys[k]
+}
+
+// keyJustPressed reports whether key k went down in this Update.
+func (in *inputState) keyJustPressed(k ebiten.Key) bool {
+	return in.keys[k] && !in.prevKeys[k]
+}
+
+// buttonPressed reports whether mouse button b is held down.
+func (in *inputState) buttonPressed(b ebiten.MouseButton) bool {
+	return in.buttons[b]
+}
+
+// buttonJustPressed reports whether mouse button b went down in this
+// Update.
+func (in *inputState) buttonJustPressed(b ebiten.MouseButton) bool {
+	return in.buttons[b] && !in.prevButtons[b]
+}
+
+// readInput advances g.input to this Update's input: recorded input while
+// replaying, the live keyboard and mouse otherwise. A recording in
+// progress logs the changes.
+func (g *Game) readInput() {
+	prev := g.input
+	var in inputState
+	if g.replay != nil {
+		in = g.replay.next(prev)
+		if g.replay.done() {
+			g.replay = nil
+			log.Printf("replay finished; back to live input")
+		}
+	} else {
+		in = liveInput()
+		in.cursor = in.cursor.Sub(g.origin)
+		// The buttons and the wheel only act on the game the cursor is
+		// over.
+		if !g.onScreen(in.cursor, 0) {
+			in.buttons, in.wheel = map[ebiten.MouseButton]bool{}, 0
+		}
+	}
+	in.prevKeys, in.prevButtons = prev.keys, prev.buttons
+	if g.inputLog != nil {
+		g.inputLog.record(g.frame, prev, in)
+	}
+	g.input = in
+	g.frame++
+}
@@ -0,0 +1,75 @@
+package main
+
+// ----------------------------------------------------
+// Render interpolation.
+// ----------------------------------------------------
+
+// snapshot is the state before the latest physics step: what Draw
+// interpolates from toward the current state.
+type snapshot struct {
+	balls    []Vector  // Ball positions.
+	rotation float64   // Container rotation.
+	rings    []float64 // Ring rotations.
+}
+
+// takeSnapshot remembers the current state before a physics step.
+func (g *Game) takeSnapshot() {
+	if !g.interpolate {
+		return
+	}
+	s := &g.prev
+	s.balls = s.balls[:0]
+	for _, b := range g.world.Balls {
+		s.balls = append(s.balls, b.Pos)
+	}
+	s.rotation = g.world.Rotation
+	s.rings = s.rings[:0]
+	for _, r := range g.world.Rings {
+		s.rings = append(s.rings, r.Rotation)
+	}
+	g.prevValid = true
+}
+
+// dropSnapshot forgets the snapshot after the state jumped (a reset, a
+// resize, a loaded state), so Draw doesn't blend across the jump.
+func (g *Game) dropSnapshot() {
+	g.prevValid = false
+}
+
+// renderAlpha returns how far between the snapshot (0) and the current
+// state (1) Draw should show the scene: the fraction of a physics step
+// the accumulator holds. Without a snapshot, or when the physics doesn't
+// run on the accumulator, it shows the current state.
+func (g *Game) renderAlpha() float64 {
+	if !g.interpolate || !g.prevValid || g.paused || g.lockstep ||
+		len(g.prev.balls) != len(g.world.Balls) || len(g.prev.rings) != len(g.world.Rings) {
+		return 1
+	}
+	return g.accumulator / physicsDT
+}
+
+// renderPos returns where Draw shows ball number i.
+func (g *Game) renderPos(i int) Vector {
+	b := g.world.Balls[i]
+	alpha := g.renderAlpha()
+	if alpha == 1 {
+		return b.Pos
+	}
+	return g.prev.balls[i].Lerp(b.Pos, alpha)
+}
+
+// renderRotation returns the container rotation Draw shows.
+func (g *Game) renderRotation() float64 {
+	alpha := g.renderAlpha()
+	return g.prev.rotation + (g.world.Rotation-g.prev.rotation)*alpha
+}
+
+// ringRenderRotation returns the rotation Draw shows for ring k.
+func (g *Game) ringRenderRotation(k int) float64 {
+	r := g.world.Rings[k]
+	alpha := g.renderAlpha()
+	if alpha == 1 {
+		return r.Rotation
+	}
+	return g.prev.rings[k] + (r.Rotation-g.prev.rings[k])*alpha
+}
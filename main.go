@@ -1,271 +1,1370 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"image/color"
+	"log"
 	"math"
+	"math/rand"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"michelo851a1203/hex-motion/physics"
 )
 
 // ----------------------------------------------------
-// 1. Constants and Vector type
+// 1. Constants and types
 // ----------------------------------------------------
 
-// Define the window dimensions.
+// The physics always advances in fixed steps of physicsDT seconds, no
+// matter how often Ebiten calls Update. maxFrameTime caps how much real
+// time a single Update may catch up on (e.g. after the window was dragged).
 const (
-	screenWidth  = 800
-	screenHeight = 600
+	physicsDT    = 1.0 / 60.0
+	maxFrameTime = 0.25
 )
 
-// Vector is a simple 2D vector type with helper methods.
-type Vector struct {
-	X, Y float64
-}
+// Wall heat gradient end points.
+var (
+	wallCoolColor = color.RGBA{80, 130, 255, 255}
+	wallHotColor  = color.RGBA{255, 90, 30, 255}
+)
 
-// Basic vector operations.
-func (v Vector) Add(u Vector) Vector {
-	return Vector{v.X + u.X, v.Y + u.Y}
-}
+// Speed-colored trails run from trailSlowColor at rest to trailFastColor
+// at trailFastSpeed (px/s) and above.
+var (
+	trailSlowColor = color.RGBA{60, 120, 255, 255}
+	trailFastColor = color.RGBA{255, 50, 40, 255}
+)
 
-func (v Vector) Sub(u Vector) Vector {
-	return Vector{v.X - u.X, v.Y - u.Y}
-}
+const trailFastSpeed = 800.0
 
-func (v Vector) Mul(s float64) Vector {
-	return Vector{v.X * s, v.Y * s}
-}
+// The game works in the physics package's types throughout.
+type (
+	Vector   = physics.Vector
+	Ball     = physics.Ball
+	Material = physics.Material
+)
+
+// ----------------------------------------------------
+// 2. The Game struct holds our simulation state
+// ----------------------------------------------------
+
+type Game struct {
+	// The configuration the game was built from; reset returns to it.
+	cfg Config
+
+	// The configuration from the command line, which presets build on,
+	// the index of the current preset (-1 before the first), and a banner
+	// shown until frame bannerUntil.
+	baseCfg     Config
+	preset      int
+	banner      string
+	bannerUntil int
+
+	// The simulation's source of randomness, seeded from cfg.Seed (and
+	// reseeded on reset) so runs are reproducible.
+	rng *rand.Rand
+
+	// The simulation itself: balls, container and physics parameters.
+	world *physics.World
+
+	// Color a fully heated ball is tinted toward.
+	hotColor color.RGBA
+
+	// Color each wall by its heat instead of the wall color.
+	enableWallHeat bool
+
+	// Colors of the background, walls and balls (cycled with T).
+	theme Theme
+
+	// Wall appearance. Collisions still treat walls as zero-thickness
+	// segments; thickness is purely visual. The color is the theme's
+	// unless cfg.WallColor overrides it.
+	wallThickness float64
+	wallColor     color.Color
+
+	// Pre-rendered ball images, shared by every ball of the same size and
+	// color (see cachedImage). They are created by Draw on first use, so
+	// a Game that is only stepped (e.g. in a benchmark) never touches the
+	// graphics driver.
+	images map[imageKey]*ebiten.Image
+
+	// Fixed-timestep bookkeeping: real time not yet simulated and when
+	// Update last ran.
+	accumulator float64
+	lastTick    time.Time
+
+	// Simulated seconds per real second; every fixed step advances the
+	// physics by physicsDT*timeScale (halve/double with [ and ]).
+	timeScale float64
+
+	// Number of physics sub-steps each step is split into. More sub-steps
+	// keep collisions accurate when the walls move fast, at a
+	// proportional cost.
+	substeps int
+
+	// Simulated time (seconds), the scripted events, and the index of
+	// the next event to play.
+	simTime    float64
+	script     []scriptEvent
+	scriptNext int
+
+	// When paused, Update skips all physics; Draw keeps showing the
+	// frozen frame (toggled with P). Period asks for a single step while
+	// paused.
+	paused      bool
+	stepPending bool
+
+	// Diagnostics: when set, Draw only clears the screen while the
+	// physics keeps running (toggled with F9).
+	skipRender bool
+
+	// Energy check: when enabled, every bounce that raises a ball's
+	// energy by more than physics.EnergyTolerance is counted and logged.
+	energyCheck    bool
+	energyGains    int
+	lastEnergyGain float64
+
+	// Freeze on hit: when set, the first wall collision pauses the game,
+	// with the debug HUD on to show the contact (toggled with Shift+P).
+	freezeOnHit bool
+
+	// Set by F12; the next Draw saves the frame as a PNG.
+	screenshotPending bool
+
+	// GIF recording in progress (nil when not recording; toggled with
+	// F10) and the frame limit for new recordings.
+	recorder     *gifRecorder
+	gifMaxFrames int
+
+	// Debug HUD with live numbers in the top-left corner (toggled with F3).
+	showHUD bool
+
+	// Settings overlay (toggled with Tab) and the setting selected in it.
+	showSettings bool
+	settingIndex int
+
+	// Speed gauge in the corner (toggled with D) and the speed that
+	// fills it.
+	showSpeedometer bool
+	gaugeMax        float64
+
+	// Trails colored by the ball's speed instead of its own color
+	// (toggled with K).
+	speedTrails bool
+
+	// Background grid for spatial reference (toggled with B), with its
+	// line spacing in pixels.
+	showGrid    bool
+	gridSpacing float64
+
+	// View: when set, Draw counter-rotates the scene so the hexagon
+	// appears stationary (toggled with V). Physics is unaffected.
+	rotatingFrame bool
 
-func (v Vector) Dot(u Vector) float64 {
-	return v.X*u.X + v.Y*u.Y
+	// Camera zoom and pan applied on top of the view (mouse wheel and
+	// middle-drag, C to reset), and the cursor position a pan continues
+	// from.
+	cameraScale  float64
+	cameraOffset Vector
+	panFrom      Vector
+
+	// Current logical screen size, following the window as it is resized
+	// (or fixed at the configured render size).
+	screenW, screenH int
+
+	// Where the game's screen starts in the window (the right half of a
+	// split screen starts at its middle); the cursor is read relative to
+	// it.
+	origin Vector
+
+	// Whether the game handles the window and file keys (F5 to F8 and
+	// F10 to F12), which only one game in a split screen may.
+	windowKeys bool
+
+	// Window size from before going fullscreen (F11), restored when
+	// leaving it.
+	windowW, windowH int
+
+	// OnCollision, if set, is called once for every wall collision the
+	// physics resolves, with the wall's index and the impact speed (the
+	// ball's speed into the wall, relative to it). A ball resting against
+	// a wall only triggers it when the contact begins.
+	OnCollision func(ball *Ball, edgeIndex int, impactSpeed float64)
+
+	// OnExit, if set, is called when a ball escapes through an open wall.
+	OnExit func(ball *Ball, edgeIndex int)
+
+	// File every wall collision is logged to, if any (-collision-log).
+	collisionLog *collisionLog
+
+	// Balls escaped through open walls so far, and how many walls the
+	// openings have moved on since the start.
+	exits     int
+	gateShift int
+
+	// Angular speed a varying spin swings around, and how many spin
+	// periods it has run (see updateSpin).
+	spinBase  float64
+	spinPhase float64
+
+	// Mouse force: +1 while pulling, -1 while pushing, 0 otherwise, and
+	// the cursor in world coordinates.
+	mouseSign float64
+	mousePos  Vector
+
+	// Gravity well at the container's center (toggled with W), its
+	// strength, and the breeze (nil for none); both feed the World's
+	// force field.
+	enableGravityWell bool
+	wellStrength      float64
+	wind              func(pos Vector, t float64) Vector
+
+	// Distinct wall collisions since the last reset (a resting contact
+	// counts once).
+	collisions int
+
+	// Sparks thrown off by collisions, when enabled.
+	particlesOn bool
+	particles   []Particle
+	// The sparks' own randomness, so turning them on or off leaves the
+	// simulation's draws from rng untouched.
+	particleRNG *rand.Rand
+
+	// Contact points and normals of recent collisions, drawn with the
+	// debug HUD.
+	contacts []contactMarker
+
+	// This Update's keyboard and mouse input, the number of Updates so
+	// far, and the input recording being made or played back, if any.
+	// Either one puts the physics in lockstep with Update, one fixed step
+	// per call.
+	input    inputState
+	frame    int
+	inputLog *inputRecording
+	replay   *replayer
+	lockstep bool
+
+	// Draw blends from the state before the latest step (prev, when
+	// prevValid) to the current one by the accumulator's remainder, so
+	// motion looks smooth at any frame rate.
+	interpolate bool
+	prev        snapshot
+	prevValid   bool
+
+	// Balls still deformed by a recent hit.
+	squashes map[*Ball]squash
+
+	// Where the balls have been, always accumulating and shown on request
+	// (toggled with H, cleared with Shift+H).
+	heatmap     *heatmap
+	showHeatmap bool
 }
 
-func (v Vector) Len() float64 {
-	return math.Hypot(v.X, v.Y)
+// NewGame initializes our simulation from cfg, drawn in the given theme.
+func NewGame(cfg Config, theme Theme) (*Game, error) {
+	g := &Game{
+		cfg:     cfg,
+		baseCfg: cfg,
+		preset:  -1,
+		world: &physics.World{
+			BallRadius:      cfg.BallRadius,
+			CollisionRadius: math.Max(0, cfg.BallRadius+cfg.CollisionMargin),
+			BallCollisions:  cfg.BallCollisions,
+
+			Drag:          cfg.Drag,
+			Friction:      cfg.Friction,
+			GrazingFactor: cfg.GrazingFactor,
+			Materials:     cfg.Materials,
+			MaxSpeed:      cfg.MaxSpeed,
+			NoContainer:   cfg.NoContainer,
+
+			HeatPerSpeed:  cfg.HeatPerSpeed,
+			HeatDecay:     cfg.HeatDecay,
+			WallHeatDecay: cfg.WallHeatDecay,
+		},
+		hotColor:       color.RGBA{255, 230, 120, 255},
+		enableWallHeat: cfg.WallHeat,
+
+		wallThickness: cfg.WallThickness,
+
+		particlesOn: cfg.Particles,
+		squashes:    map[*Ball]squash{},
+		images:      map[imageKey]*ebiten.Image{},
+		interpolate: cfg.Interpolate,
+		heatmap:     newHeatmap(cfg.RenderSize()),
+		showGrid:    cfg.Grid,
+		speedTrails: cfg.SpeedTrails,
+		gridSpacing: cfg.GridSpacing,
+
+		showSpeedometer: cfg.Speedometer,
+		gaugeMax:        cfg.GaugeMax,
+
+		substeps: max(1, cfg.Substeps),
+
+		energyCheck:  cfg.EnergyCheck,
+		freezeOnHit:  cfg.FreezeOnHit,
+		gifMaxFrames: max(1, cfg.GIFFrames),
+
+		enableGravityWell: cfg.GravityWell,
+		wellStrength:      cfg.WellStrength,
+
+		cameraScale: 1,
+
+		windowKeys: true,
+	}
+	g.screenW, g.screenH = cfg.RenderSize()
+	g.bindWorld()
+	if cfg.Wind != 0 {
+		g.wind = breeze(cfg.Wind)
+	}
+	if cfg.RestitutionFalloff > 0 {
+		// Harder hits keep less of their speed: the restitution halves at
+		// the falloff speed.
+		g.world.RestitutionFn = func(speed float64) float64 {
+			return 1 / (1 + speed/cfg.RestitutionFalloff)
+		}
+	}
+	// Built-in containers are centered on the screen; custom polygons
+	// rotate about their own centroid.
+	center := Vector{X: float64(g.screenW) / 2, Y: float64(g.screenH) / 2}
+	switch {
+	case len(cfg.Points) > 0:
+		poly, err := physics.NewPolygon(cfg.Points)
+		if err != nil {
+			return nil, err
+		}
+		g.world.SetContainer(poly)
+	case cfg.Container == "circle":
+		g.world.SetContainer(physics.NewCircle(center, cfg.HexRadius))
+	default:
+		g.world.SetContainer(physics.NewRegularPolygon(center, cfg.HexRadius, cfg.Sides))
+	}
+	g.world.SetRings(g.newRings())
+	g.world.Box = g.screenBox()
+	g.setTheme(theme)
+	g.reset()
+	// A small container or custom polygon may not have room for the
+	// usual starting point, or any room at all.
+	if start, moved := g.startPoint(); moved {
+		if !g.world.FitsInside(start, 0) {
+			return nil, errors.New("the ball doesn't fit inside the container")
+		}
+		log.Printf("the ball's usual starting point is outside the container; starting it at the center")
+	}
+	return g, nil
 }
 
-func (v Vector) Normalize() Vector {
-	l := v.Len()
-	if l == 0 {
-		return Vector{0, 0}
+// bindWorld points the world's hooks at g. They are method values bound
+// to the Game they were taken from, so a Game copied over another (see
+// nextPreset) has to rebind them.
+func (g *Game) bindWorld() {
+	g.world.OnCollision = g.onCollision
+	if g.cfg.EnergyCheck {
+		g.world.OnEnergyGain = g.checkEnergy
 	}
-	return Vector{v.X / l, v.Y / l}
+	g.world.OnEscape = g.logEscape
+	g.world.OnExit = g.onExit
+	g.world.Accel = g.mouseAccel
+	g.world.Field = g.forceField
 }
 
-// Perp returns a perpendicular vector (rotated 90° counterclockwise).
-func (v Vector) Perp() Vector {
-	return Vector{-v.Y, v.X}
+// imageKey identifies a pre-rendered ball image.
+type imageKey struct {
+	spin   bool // The spin marker overlay rather than the ball itself.
+	radius int
+	color  color.RGBA
 }
 
-// ----------------------------------------------------
-// 2. The Game struct holds our simulation state
-// ----------------------------------------------------
+// cachedImage returns the image for key, rendering it on first use: the
+// ball, flat or shaded as configured, or the overlay drawn rotated by the
+// ball's angle so its spin shows (kept separate so the shading's
+// highlight stays put).
+func (g *Game) cachedImage(key imageKey) *ebiten.Image {
+	if img, ok := g.images[key]; ok {
+		return img
+	}
+	var img *ebiten.Image
+	switch {
+	case key.spin:
+		img = createSpinMarkerImage(key.radius)
+	case g.cfg.Shaded:
+		rad := g.cfg.LightAngle * math.Pi / 180
+		light := Vector{X: math.Cos(rad), Y: math.Sin(rad)}
+		img = createShadedBallImage(key.radius, key.color, light)
+	default:
+		img = createCircleImage(key.radius, key.color)
+	}
+	g.images[key] = img
+	return img
+}
 
-type Game struct {
-	// Ball properties.
-	ballPos Vector // Position of the ball.
-	ballVel Vector // Velocity of the ball.
-	ballRadius float64
+// reset puts the simulation back in its starting state: freshly spawned
+// balls, the hexagon's rotation, the parameters that can be changed at
+// runtime, heat, statistics, and the script clock.
+func (g *Game) reset() {
+	g.rng = rand.New(rand.NewSource(g.cfg.Seed))
+	g.particleRNG = rand.New(rand.NewSource(g.cfg.Seed))
 
-	// Hexagon properties.
-	hexRotation      float64 // Current rotation angle (in radians).
-	hexAngularSpeed  float64 // Angular speed (radians per second).
-	hexRadius        float64 // Distance from hexagon center to a vertex.
+	w := g.world
+	w.Rotation, w.Time = 0, 0
+	g.spawnBalls(g.cfg.Balls)
+	w.AngularSpeed = g.cfg.AngularSpeed
+	g.spinBase, g.spinPhase = g.cfg.AngularSpeed, 0
+	for k, r := range w.Rings {
+		r.Rotation, r.AngularSpeed = 0, g.ringSpeed(k)
+	}
+	g.openGates(0)
+	g.exits = 0
+	g.collisions = 0
 
-	// Pre-rendered image for the ball.
-	circleImage *ebiten.Image
-}
+	w.Gravity = g.cfg.Gravity
+	w.GravityDir = Vector{X: 1, Y: 0}.Rotate(g.cfg.GravityAngle * math.Pi / 180)
+	w.GravityOn = !g.cfg.NoGravity
+	w.Restitution = g.cfg.Restitution
+	g.timeScale = 1
 
-// NewGame initializes our simulation.
-func NewGame() *Game {
-	g := &Game{
-		// Start the ball a bit above the hexagon center.
-		ballPos:          Vector{X: screenWidth / 2, Y: screenHeight/2 - 150},
-		// Give it an initial horizontal push.
-		ballVel:          Vector{X: 100, Y: 0},
-		ballRadius:       10,
+	for i := range w.WallHeat {
+		w.WallHeat[i] = 0
+	}
+	w.Stats = physics.Stats{}
+	g.energyGains, g.lastEnergyGain = 0, 0
+	g.particles = g.particles[:0]
+	g.contacts = g.contacts[:0]
+	clear(g.squashes)
+	g.heatmap.clear()
+	g.dropSnapshot()
+	g.simTime = 0
+	g.scriptNext = 0
+	g.accumulator = 0
+}
 
-		// The hexagon is centered on the screen.
-		hexRotation:      0,
-		hexAngularSpeed:  0.5,  // Rotate at 0.5 rad/s (adjust as desired).
-		hexRadius:        200,  // Radius of the hexagon.
+// setTheme switches to theme t, recoloring the walls (unless their color
+// is configured) and the balls.
+func (g *Game) setTheme(t Theme) {
+	g.theme = t
+	g.wallColor = t.Wall
+	if g.cfg.WallColor.A != 0 {
+		g.wallColor = g.cfg.WallColor
 	}
-	// Create a red circle image to represent the ball.
-	g.circleImage = createCircleImage(int(g.ballRadius), color.RGBA{255, 0, 0, 255})
-	return g
+	g.colorBalls()
 }
 
 // ----------------------------------------------------
 // 3. Helper: Create a filled circle image.
 // ----------------------------------------------------
 
-// createCircleImage creates an image with a filled circle of the given radius and color.
+// createCircleImage creates an image with a filled, anti-aliased circle of
+// the given radius and color.
 func createCircleImage(radius int, clr color.Color) *ebiten.Image {
+	diameter := 2 * radius
+	// New images start out transparent.
+	img := ebiten.NewImage(diameter, diameter)
+	r := float32(radius)
+	vector.DrawFilledCircle(img, r, r, r, clr, true)
+	return img
+}
+
+// createSpinMarkerImage creates a transparent image of the given radius
+// with a dark radial line from the center to the right edge.
+func createSpinMarkerImage(radius int) *ebiten.Image {
+	diameter := 2 * radius
+	img := ebiten.NewImage(diameter, diameter)
+	r := float32(radius)
+	width := max(1, r/5)
+	vector.StrokeLine(img, r, r, 2*r-width/2, r, width, color.NRGBA{0, 0, 0, 110}, true)
+	return img
+}
+
+// createShadedBallImage creates a ball image shaded like a lit sphere: a
+// diffuse falloff toward the rim plus a specular highlight offset toward
+// light, the on-screen direction the light comes from.
+func createShadedBallImage(radius int, clr color.Color, light Vector) *ebiten.Image {
 	diameter := 2 * radius
 	img := ebiten.NewImage(diameter, diameter)
-	// Clear the image (transparent).
-	img.Fill(color.Transparent)
-	// Draw the circle pixel by pixel.
+	img.WritePixels(shadedBallPixels(radius, clr, light))
+	return img
+}
+
+// shadedBallPixels returns the pixels of createShadedBallImage's ball, as
+// row-major premultiplied RGBA.
+func shadedBallPixels(radius int, clr color.Color, light Vector) []byte {
+	diameter := 2 * radius
+
+	// Tilt the light out of the screen plane so the lit side faces the viewer.
+	l := light.Normalize()
+	lx, ly, lz := l.X*0.7, l.Y*0.7, 0.7
+	ln := math.Sqrt(lx*lx + ly*ly + lz*lz)
+	lx, ly, lz = lx/ln, ly/ln, lz/ln
+
+	// Shade into an RGBA buffer (premultiplied, like clr.RGBA's values) to upload in one go;
+	// setting the pixels on the image one at a time is much slower.
+	pix := make([]byte, 4*diameter*diameter)
+	cr, cg, cb, ca := clr.RGBA()
 	for y := 0; y < diameter; y++ {
 		for x := 0; x < diameter; x++ {
-			dx := float64(x - radius)
-			dy := float64(y - radius)
-			if dx*dx+dy*dy <= float64(radius*radius) {
-				img.Set(x, y, clr)
+			nx := (float64(x) + 0.5 - float64(radius)) / float64(radius)
+			ny := (float64(y) + 0.5 - float64(radius)) / float64(radius)
+			d2 := nx*nx + ny*ny
+			if d2 > 1 {
+				continue
+			}
+			// Surface normal of the sphere at this pixel.
+			nz := math.Sqrt(1 - d2)
+			diffuse := math.Max(0, nx*lx+ny*ly+nz*lz)
+			specular := math.Pow(diffuse, 24)
+			shade := 0.25 + 0.75*diffuse
+			channel := func(c uint32) byte {
+				v := float64(c>>8)*shade + 255*specular*0.6
+				return byte(math.Min(255, v))
 			}
+			i := 4 * (y*diameter + x)
+			pix[i], pix[i+1], pix[i+2], pix[i+3] = channel(cr), channel(cg), channel(cb), byte(ca>>8)
 		}
 	}
-	return img
+	return pix
+}
+
+// parseHexColor parses a color written as "#rrggbb".
+func parseHexColor(s string) (color.RGBA, error) {
+	var c color.RGBA
+	if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+		return c, fmt.Errorf("invalid color %q: want #rrggbb", s)
+	}
+	c.A = 255
+	return c, nil
+}
+
+// formatHexColor formats c as "#rrggbb".
+func formatHexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// onOff formats a setting for the HUD.
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
+
+// lerpColor blends a toward b by t (0 gives a, 1 gives b).
+func lerpColor(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), mix(a.A, b.A)}
 }
 
 // ----------------------------------------------------
-// 4. The Update method: Physics and collision handling.
+// 4. Update and step: Physics and collision handling.
 // ----------------------------------------------------
 
 func (g *Game) Update() error {
-	// We'll assume a fixed time step.
-	dt := 1.0 / 60.0
-
-	// Apply gravity to the ball (gravity pulls downward).
-	gravity := 500.0 // pixels per second²
-	g.ballVel.Y += gravity * dt
-
-	// Apply a little air friction (damping) to slow the ball over time.
-	airFriction := 0.99
-	g.ballVel = g.ballVel.Mul(airFriction)
-
-	// Update the ball's position.
-	g.ballPos = g.ballPos.Add(g.ballVel.Mul(dt))
-
-	// Update the hexagon’s rotation.
-	g.hexRotation += g.hexAngularSpeed * dt
-
-	// Compute the hexagon vertices (in screen coordinates).
-	hexVertices := g.getHexagonVertices()
-
-	// For each of the 6 edges, check for collision with the ball.
-	// We'll use a restitution coefficient to simulate energy loss on impact.
-	restitution := 0.9
-	for i := 0; i < 6; i++ {
-		A := hexVertices[i]
-		B := hexVertices[(i+1)%6]
-		// Find the closest point on the edge AB to the ball’s center.
-		closest := closestPointOnSegment(A, B, g.ballPos)
-		// Compute the vector from this point to the ball center.
-		diff := g.ballPos.Sub(closest)
-		dist := diff.Len()
-		if dist < g.ballRadius {
-			// --- Collision detected ---
-			penetration := g.ballRadius - dist
-			var normal Vector
-			if dist != 0 {
-				// Normal from the collision point toward the ball.
-				normal = diff.Normalize()
-			} else {
-				// If the ball’s center is exactly on the edge, use the edge’s perpendicular.
-				edge := B.Sub(A)
-				normal = edge.Perp().Normalize()
-			}
+	g.readInput()
 
-			// Correct the ball's position so it's no longer penetrating the wall.
-			g.ballPos = g.ballPos.Add(normal.Mul(penetration))
-
-			// To simulate a "realistic" collision with a moving wall, we
-			// compute the wall’s velocity at the collision point.
-			hexCenter := Vector{X: screenWidth / 2, Y: screenHeight / 2}
-			r := closest.Sub(hexCenter)
-			// For a rotating body, the velocity at point r is omega × r.
-			// In 2D, this gives: wallVel = omega * (-r.Y, r.X)
-			wallVel := r.Perp().Mul(g.hexAngularSpeed)
-
-			// Compute the ball’s velocity relative to the moving wall.
-			relVel := g.ballVel.Sub(wallVel)
-			// Check if the ball is moving into the wall (dot product is negative).
-			dot := relVel.Dot(normal)
-			if dot < 0 {
-				// Reflect the relative velocity about the collision normal.
-				relVel = relVel.Sub(normal.Mul((1+restitution)*dot))
-				// The new ball velocity is the reflected relative velocity plus the wall’s velocity.
-				g.ballVel = relVel.Add(wallVel)
-			}
+	// Quitting finishes a GIF recording in progress before the game loop
+	// ends.
+	if g.quitRequested() {
+		if g.recorder != nil {
+			g.stopRecording()
 		}
+		return ebiten.Termination
+	}
+	g.handleInput()
+
+	// Accumulate the real time elapsed since the last Update and run as
+	// many fixed physics steps as it covers; the remainder carries over.
+	// Time spent paused is dropped rather than caught up on afterwards.
+	// In lockstep every Update runs exactly one step instead.
+	now := time.Now()
+	if g.paused {
+		g.lastTick = now
+		if g.stepPending {
+			g.stepPending = false
+			g.step(physicsDT * g.timeScale)
+		}
+		return nil
+	}
+	if g.lockstep {
+		g.step(physicsDT * g.timeScale)
+		return nil
+	}
+	if !g.lastTick.IsZero() {
+		g.accumulator += math.Min(now.Sub(g.lastTick).Seconds(), maxFrameTime)
+	}
+	g.lastTick = now
+	// A collision freezing the game stops the catching up right there.
+	for g.accumulator >= physicsDT && !g.paused {
+		g.takeSnapshot()
+		g.step(physicsDT * g.timeScale)
+		g.accumulator -= physicsDT
+	}
+	if g.paused {
+		g.accumulator = 0
 	}
 	return nil
 }
 
+// Ranges and increments for the runtime controls.
+const (
+	maxGravity      = 5000.0
+	gravityStep     = 100.0
+	maxAngularSpeed = 10.0
+	angularStep     = 0.25
+	gravityTurn     = math.Pi / 12 // 15°
+	minTimeScale    = 1.0 / 64
+	maxTimeScale    = 4.0
+)
+
+// tpsOptions lists the tick rates F7 cycles through.
+var tpsOptions = []int{30, 60, 120}
+
+// quitRequested reports whether the user asked to quit, with Escape,
+// Ctrl+Q or the window's close button.
+func (g *Game) quitRequested() bool {
+	return g.input.keyJustPressed(ebiten.KeyEscape) ||
+		g.input.keyPressed(ebiten.KeyControl) && g.input.keyJustPressed(ebiten.KeyQ) ||
+		ebiten.IsWindowBeingClosed()
+}
+
+// handleInput processes the keyboard and mouse controls:
+//
+//	Escape / Ctrl+Q     quit
+//	Tab                 open / close the settings overlay, where up/down
+//	                    select a setting and left/right adjust it
+//	Left/Right or -/+   slow down / speed up the hexagon's rotation
+//	G / Shift+G         raise / lower gravity
+//	Q / E               rotate gravity's direction counterclockwise / clockwise
+//	Space               toggle gravity on and off (unless -no-gravity)
+//	W                   toggle the gravity well at the center
+//	M                   cycle collisions through normal, elastic and inelastic
+//	[ / ]               halve / double the time scale (slow motion)
+//	P                   pause / resume
+//	Shift+P             toggle freezing at the next wall collision
+//	.                   advance one physics step while paused
+//	R                   reset to the starting state
+//	V                   toggle the rotating-frame view
+//	T                   switch to the next color theme
+//	N                   switch to the next preset scenario
+//	B                   toggle the background grid
+//	K                   toggle coloring the trails by speed
+//	D                   toggle the speedometer
+//	H / Shift+H         toggle / clear the position heatmap
+//	left / right mouse  pull the balls toward / push them away from the cursor
+//	mouse wheel         zoom in / out toward the cursor
+//	middle mouse drag   pan the camera
+//	C                   reset the camera
+//	F3                  toggle the debug HUD, velocity arrows and contact markers
+//	F5 / F6             save / load the state in state.json
+//	F7                  cycle the tick rate through 30, 60 and 120 TPS
+//	F8                  toggle VSync
+//	F9                  toggle rendering (physics keeps running)
+//	F10                 start / stop recording a GIF
+//	F11                 toggle fullscreen
+//	F12                 save a PNG screenshot
+func (g *Game) handleInput() {
+	g.updateCamera()
+	g.updateMouse()
+	if g.input.keyJustPressed(ebiten.KeyTab) {
+		g.showSettings = !g.showSettings
+	}
+	if g.showSettings {
+		g.updateSettings()
+	}
+	arrows := !g.showSettings // The open overlay takes the arrow keys.
+	if arrows && g.input.keyJustPressed(ebiten.KeyRight) || g.input.keyJustPressed(ebiten.KeyEqual) ||
+		g.input.keyJustPressed(ebiten.KeyNumpadAdd) {
+		g.setAngularSpeed(g.baseAngularSpeed() + angularStep)
+	}
+	if arrows && g.input.keyJustPressed(ebiten.KeyLeft) || g.input.keyJustPressed(ebiten.KeyMinus) ||
+		g.input.keyJustPressed(ebiten.KeyNumpadSubtract) {
+		g.setAngularSpeed(g.baseAngularSpeed() - angularStep)
+	}
+	if g.input.keyJustPressed(ebiten.KeyG) {
+		if g.input.keyPressed(ebiten.KeyShift) {
+			g.setGravity(g.world.Gravity - gravityStep)
+		} else {
+			g.setGravity(g.world.Gravity + gravityStep)
+		}
+	}
+	// Screen y points down, so a positive rotation turns clockwise on screen.
+	if g.input.keyJustPressed(ebiten.KeyQ) && !g.input.keyPressed(ebiten.KeyControl) {
+		g.world.GravityDir = g.world.GravityDir.Rotate(-gravityTurn)
+	}
+	if g.input.keyJustPressed(ebiten.KeyE) {
+		g.world.GravityDir = g.world.GravityDir.Rotate(gravityTurn)
+	}
+	if g.input.keyJustPressed(ebiten.KeySpace) && !g.cfg.NoGravity {
+		g.world.GravityOn = !g.world.GravityOn
+	}
+	if g.input.keyJustPressed(ebiten.KeyW) {
+		g.enableGravityWell = !g.enableGravityWell
+	}
+	if g.input.keyJustPressed(ebiten.KeyM) {
+		g.world.Mode = (g.world.Mode + 1) % (physics.CollisionsInelastic + 1)
+	}
+	if g.input.keyJustPressed(ebiten.KeyBracketLeft) {
+		g.timeScale = math.Max(minTimeScale, g.timeScale/2)
+	}
+	if g.input.keyJustPressed(ebiten.KeyBracketRight) {
+		g.timeScale = math.Min(maxTimeScale, g.timeScale*2)
+	}
+	if g.input.keyJustPressed(ebiten.KeyP) {
+		if g.input.keyPressed(ebiten.KeyShift) {
+			g.freezeOnHit = !g.freezeOnHit
+		} else {
+			g.paused = !g.paused
+		}
+	}
+	if g.input.keyJustPressed(ebiten.KeyPeriod) && g.paused {
+		g.stepPending = true
+	}
+	if g.input.keyJustPressed(ebiten.KeyR) {
+		g.reset()
+	}
+	if g.input.keyJustPressed(ebiten.KeyV) {
+		g.rotatingFrame = !g.rotatingFrame
+	}
+	if g.input.keyJustPressed(ebiten.KeyT) {
+		g.setTheme(nextTheme(g.theme.Name))
+	}
+	if g.input.keyJustPressed(ebiten.KeyN) {
+		g.nextPreset()
+	}
+	if g.input.keyJustPressed(ebiten.KeyC) {
+		g.resetCamera()
+	}
+	if g.input.keyJustPressed(ebiten.KeyH) {
+		if g.input.keyPressed(ebiten.KeyShift) {
+			g.heatmap.clear()
+		} else {
+			g.showHeatmap = !g.showHeatmap
+		}
+	}
+	if g.input.keyJustPressed(ebiten.KeyB) {
+		g.showGrid = !g.showGrid
+	}
+	if g.input.keyJustPressed(ebiten.KeyK) {
+		g.speedTrails = !g.speedTrails
+	}
+	if g.input.keyJustPressed(ebiten.KeyD) {
+		g.showSpeedometer = !g.showSpeedometer
+	}
+	if g.input.keyJustPressed(ebiten.KeyF3) {
+		g.showHUD = !g.showHUD
+	}
+	if g.input.keyJustPressed(ebiten.KeyF9) {
+		g.skipRender = !g.skipRender
+	}
+	if !g.windowKeys {
+		return
+	}
+	if g.input.keyJustPressed(ebiten.KeyF5) {
+		g.saveStateFile()
+	}
+	if g.input.keyJustPressed(ebiten.KeyF6) {
+		g.loadStateFile()
+	}
+	if g.input.keyJustPressed(ebiten.KeyF7) {
+		g.setTPS(nextTPS(ebiten.TPS()))
+	}
+	if g.input.keyJustPressed(ebiten.KeyF8) {
+		ebiten.SetVsyncEnabled(!ebiten.IsVsyncEnabled())
+	}
+	if g.input.keyJustPressed(ebiten.KeyF10) {
+		if g.recorder == nil {
+			g.recorder = newGIFRecorder(g.gifMaxFrames)
+		} else {
+			g.stopRecording()
+		}
+	}
+	if g.input.keyJustPressed(ebiten.KeyF11) {
+		g.toggleFullscreen()
+	}
+	if g.input.keyJustPressed(ebiten.KeyF12) {
+		g.screenshotPending = true
+	}
+}
+
+// toggleFullscreen switches between fullscreen and a window. Leaving
+// fullscreen restores the window size from before, or the configured size
+// if there was none. Layout re-lays out the scene either way.
+func (g *Game) toggleFullscreen() {
+	if ebiten.IsFullscreen() {
+		ebiten.SetFullscreen(false)
+		w, h := g.windowW, g.windowH
+		if w <= 0 || h <= 0 {
+			w, h = g.cfg.ScreenWidth, g.cfg.ScreenHeight
+		}
+		ebiten.SetWindowSize(w, h)
+		return
+	}
+	g.windowW, g.windowH = ebiten.WindowSize()
+	ebiten.SetFullscreen(true)
+}
+
+// stopRecording ends the current GIF recording and writes it to disk.
+func (g *Game) stopRecording() {
+	name, err := g.recorder.save()
+	g.recorder = nil
+	if err != nil {
+		log.Printf("saving GIF: %v", err)
+		return
+	}
+	log.Printf("saved %s", name)
+}
+
+// setGravity sets the gravity strength, clamped to [0, maxGravity].
+func (g *Game) setGravity(v float64) {
+	g.world.Gravity = math.Max(0, math.Min(maxGravity, v))
+}
+
+// setAngularSpeed sets the hexagon's angular speed, or the base speed of a
+// varying spin, clamped to ±maxAngularSpeed.
+func (g *Game) setAngularSpeed(v float64) {
+	v = math.Max(-maxAngularSpeed, math.Min(maxAngularSpeed, v))
+	if g.cfg.SpinMode != spinConstant {
+		g.spinBase = v
+		g.updateSpin(0)
+		return
+	}
+	g.world.AngularSpeed = v
+}
+
+// setTPS sets how many times per second Ebiten calls Update, ignoring
+// rates below one. The physics keeps its fixed step, so only the number of
+// steps per Update changes.
+func (g *Game) setTPS(tps int) {
+	if tps < 1 {
+		return
+	}
+	ebiten.SetTPS(tps)
+}
+
+// nextTPS returns the tick rate after tps in tpsOptions, wrapping around.
+// A rate not in the list moves on to the first option.
+func nextTPS(tps int) int {
+	for i, t := range tpsOptions {
+		if t == tps {
+			return tpsOptions[(i+1)%len(tpsOptions)]
+		}
+	}
+	return tpsOptions[0]
+}
+
+// Advance runs the simulation for the given simulated seconds, rounded to
+// whole fixed steps, through the same steps Update takes, but without
+// input, pausing or the frame clock. It lets tests and tools run the game
+// headlessly and deterministically.
+func (g *Game) Advance(seconds float64) {
+	dt := physicsDT * g.timeScale
+	for range int(math.Round(seconds / dt)) {
+		g.takeSnapshot()
+		g.step(dt)
+	}
+}
+
+// step advances the simulation by dt seconds: scripted events, the
+// openings, the physics, the collision sparks and squashes, and
+// respawning escaped balls.
+func (g *Game) step(dt float64) {
+	// Play any scripted events that are due.
+	g.simTime += dt
+	g.runScript()
+	g.updateGates()
+	g.updateSpin(dt)
+
+	g.updateParticles(dt)
+	g.updateContacts(dt)
+	g.updateSquashes(dt)
+	// Each sub-step advances the rotation, integrates the balls and runs
+	// collision handling.
+	for range g.substeps {
+		g.world.Step(dt / float64(g.substeps))
+	}
+	g.updateHeatmap()
+	g.respawnEscaped()
+}
+
+// onCollision receives every collision the physics resolves.
+func (g *Game) onCollision(c physics.Collision) {
+	g.collisions++
+	if g.freezeOnHit && !g.paused {
+		g.paused, g.showHUD = true, true
+	}
+	g.squashBall(c)
+	g.markContact(c)
+	if g.collisionLog != nil {
+		g.collisionLog.record(g.frame, g.simTime, g.world.Balls, c)
+	}
+	if g.particlesOn {
+		g.spawnParticles(c.Point, c.Normal, c.ImpactSpeed, c.Ball.Color)
+	}
+	if g.OnCollision != nil {
+		g.OnCollision(c.Ball, c.Edge, c.ImpactSpeed)
+	}
+}
+
+// logEscape reports the safety net catching a ball outside the container.
+func (g *Game) logEscape(b *Ball, from Vector) {
+	log.Printf("safety net: ball escaped to (%.1f, %.1f) at t=%.2fs; moved back to (%.1f, %.1f)", from.X, from.Y, g.simTime, b.Pos.X, b.Pos.Y)
+}
+
 // ----------------------------------------------------
 // 5. The Draw method: Rendering our scene.
 // ----------------------------------------------------
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	// Fill the background with a dark color.
-	screen.Fill(color.RGBA{30, 30, 30, 255})
+	// Fill the background with the theme's color.
+	screen.Fill(g.theme.Background)
+
+	// With rendering paused only the cleared screen and a notice are shown.
+	if g.skipRender {
+		ebitenutil.DebugPrint(screen, "render paused (F9 to resume)")
+		return
+	}
+
+	// The grid goes behind everything else.
+	if g.showGrid {
+		g.drawGrid(screen)
+	}
+
+	// World-to-screen transform for the chosen reference frame.
+	view := g.viewGeoM()
+
+	if g.showHeatmap {
+		g.heatmap.draw(screen, view)
+	}
+
+	// Draw the container in the wall color and each ring in its own
+	// theme color, or every wall on a cool-to-hot gradient when wall heat
+	// is enabled.
+	if !g.world.NoContainer {
+		g.drawOutline(screen, view, g.world.Container, g.renderRotation(), 0, g.wallColor)
+	}
+	for k, r := range g.world.Rings {
+		g.drawOutline(screen, view, r.Shape, g.ringRenderRotation(k), g.world.RingWalls(k), g.theme.Rings[k%len(g.theme.Rings)])
+	}
+	g.drawScreenWalls(screen, view)
+
+	// Dotted predicted paths go behind the balls.
+	g.drawPredictions(screen, view)
 
-	// Draw the hexagon.
-	hexVertices := g.getHexagonVertices()
-	for i := 0; i < 6; i++ {
-		A := hexVertices[i]
-		B := hexVertices[(i+1)%6]
-		// Draw a white line for each edge.
-		ebitenutil.DrawLine(screen, A.X, A.Y, B.X, B.Y, color.White)
+	// Draw each ball's trail as line segments fading from transparent
+	// (oldest) to opaque (newest), in the ball's color or by speed.
+	for _, b := range g.world.Balls {
+		for i := 1; i < b.Trail.Len(); i++ {
+			clr := b.Color
+			if g.speedTrails {
+				clr = lerpColor(trailSlowColor, trailFastColor, math.Min(1, b.Trail.SpeedAt(i)/trailFastSpeed))
+			}
+			trailColor := color.NRGBA{clr.R, clr.G, clr.B, uint8(255 * i / (b.Trail.Len() - 1))}
+			drawSegment(screen, view, b.Trail.At(i-1), b.Trail.At(i), 1, trailColor)
+		}
+	}
+
+	g.drawParticles(screen, view)
+	if g.showHUD {
+		g.drawVelocityArrows(screen, view)
+		g.drawContacts(screen, view)
+	}
+
+	// Draw the balls.
+	// The images are white; each ball tints its own.
+	radius := int(g.world.BallRadius)
+	ballImage := g.cachedImage(imageKey{radius: radius, color: color.RGBA{255, 255, 255, 255}})
+	spinImage := g.cachedImage(imageKey{spin: true, radius: radius})
+	for _, i := range g.drawOrder() {
+		b := g.world.Balls[i]
+		// We offset by the radius to center the circle image at the ball's position.
+		// A recent hit squashes both images along the wall normal.
+		pos := g.renderPos(i)
+		squash := g.squashGeoM(b)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(-g.world.BallRadius, -g.world.BallRadius)
+		op.GeoM.Concat(squash)
+		op.GeoM.Translate(pos.X, pos.Y)
+		op.GeoM.Concat(view)
+		// Tint the ball according to how hot it is.
+		op.ColorScale.ScaleWithColor(lerpColor(b.Color, g.hotColor, b.Heat))
+		screen.DrawImage(ballImage, op)
+
+		// The spin marker turns with the ball about its center.
+		op = &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(-g.world.BallRadius, -g.world.BallRadius)
+		op.GeoM.Rotate(b.Angle)
+		op.GeoM.Concat(squash)
+		op.GeoM.Translate(pos.X, pos.Y)
+		op.GeoM.Concat(view)
+		screen.DrawImage(spinImage, op)
+	}
+
+	// Capture the scene (without the text overlay) for a requested
+	// screenshot and while recording.
+	if g.screenshotPending {
+		g.screenshotPending = false
+		if name, err := saveScreenshot(screen); err != nil {
+			log.Printf("saving screenshot: %v", err)
+		} else {
+			log.Printf("saved %s", name)
+		}
+	}
+	if g.recorder != nil && g.recorder.capture(screen) {
+		g.stopRecording()
 	}
 
-	// Draw the ball.
-	// We offset by the radius to center the circle image at ballPos.
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(-g.ballRadius, -g.ballRadius)
-	op.GeoM.Translate(g.ballPos.X, g.ballPos.Y)
-	screen.DrawImage(g.circleImage, op)
+	if g.showSpeedometer {
+		g.drawSpeedometer(screen)
+	}
+
+	// HUD and status labels in the top-left corner.
+	var status []string
+	if g.showSettings {
+		status = append(status, g.settingsLines()...)
+	}
+	if g.showHUD {
+		status = append(status, g.hudLines()...)
+	}
+	if g.energyGains > 0 {
+		status = append(status, fmt.Sprintf("WARNING: %d bounces gained energy (last +%.1f)", g.energyGains, g.lastEnergyGain))
+	}
+	if g.recorder != nil {
+		status = append(status, fmt.Sprintf("REC %d/%d (F10 to stop)", len(g.recorder.frames), g.gifMaxFrames))
+	}
+	if g.paused {
+		status = append(status, "PAUSED (P to resume, . to step)")
+	}
+	if g.freezeOnHit {
+		status = append(status, "freezing at the next collision (Shift+P to stop)")
+	}
+	if g.rotatingFrame {
+		status = append(status, "view: rotating frame (V for lab frame)")
+	}
+	if g.frame < g.bannerUntil {
+		status = append(status, g.banner)
+	}
+	ebitenutil.DebugPrint(screen, strings.Join(status, "\n"))
 }
 
-// Layout sets the window size.
-func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return screenWidth, screenHeight
+// hudLines returns the debug HUD text, one entry per line.
+func (g *Game) hudLines() []string {
+	angle := math.Atan2(g.world.GravityDir.Y, g.world.GravityDir.X) * 180 / math.Pi
+	gravity := fmt.Sprintf("%.0f px/s^2 at %.0f deg", g.world.Gravity, angle)
+	switch {
+	case g.cfg.NoGravity:
+		gravity += " (disabled)"
+	case !g.world.GravityOn:
+		gravity += " (off)"
+	}
+	// Position, speed and spin are shown for the first ball.
+	b := g.world.Balls[0]
+	return []string{
+		fmt.Sprintf("balls:       %d (%d escaped)", len(g.world.Balls), g.exits),
+		fmt.Sprintf("elapsed:     %.1f s, %d collisions", g.simTime, g.collisions),
+		fmt.Sprintf("pos:         (%.1f, %.1f)", b.Pos.X, b.Pos.Y),
+		fmt.Sprintf("speed:       %.1f px/s", b.Vel.Len()),
+		fmt.Sprintf("spin:        %.2f rad/s", b.AngularVel),
+		fmt.Sprintf("rotation:    %.2f rad (%.2f rad/s)", math.Mod(g.world.Rotation, 2*math.Pi), g.world.AngularSpeed),
+		fmt.Sprintf("gravity:     %s", gravity),
+		fmt.Sprintf("well:        %s (%.0f)", onOff(g.enableGravityWell), g.wellStrength),
+		fmt.Sprintf("restitution: %.2f (%s collisions)", g.world.Restitution, g.world.Mode),
+		fmt.Sprintf("energy:      %.0f", g.world.TotalEnergy()),
+		fmt.Sprintf("time scale:  %gx", g.timeScale),
+		fmt.Sprintf("FPS: %.1f  TPS: %.1f/%d  vsync: %s", ebiten.ActualFPS(), ebiten.ActualTPS(), ebiten.TPS(), onOff(ebiten.IsVsyncEnabled())),
+	}
 }
 
-// ----------------------------------------------------
-// 6. Utility: Compute hexagon vertices and segment collision.
-// ----------------------------------------------------
+// Velocity arrows: drawn velocityArrowScale seconds long, with heads
+// arrowHeadSize pixels long, and colored from cool to hot up to
+// arrowHotSpeed.
+const (
+	velocityArrowScale = 0.2
+	arrowHeadSize      = 6.0
+	arrowHotSpeed      = 1000.0
+)
+
+// drawVelocityArrows draws an arrow along each ball's velocity.
+func (g *Game) drawVelocityArrows(screen *ebiten.Image, view ebiten.GeoM) {
+	for i, b := range g.world.Balls {
+		speed := b.Vel.Len()
+		if speed == 0 {
+			continue
+		}
+		clr := lerpColor(wallCoolColor, wallHotColor, math.Min(1, speed/arrowHotSpeed))
+		pos := g.renderPos(i)
+		drawArrow(screen, view, pos, pos.Add(b.Vel.Mul(velocityArrowScale)), clr)
+	}
+}
+
+// drawArrow draws an arrow from tail to tip, with its head at the tip.
+func drawArrow(screen *ebiten.Image, view ebiten.GeoM, tail, tip Vector, clr color.Color) {
+	drawSegment(screen, view, tail, tip, 2, clr)
+	// The head's two strokes point back from the tip at ±30°.
+	back := tip.Sub(tail).Normalize().Mul(-arrowHeadSize)
+	drawSegment(screen, view, tip, tip.Add(back.Rotate(math.Pi/6)), 2, clr)
+	drawSegment(screen, view, tip, tip.Add(back.Rotate(-math.Pi/6)), 2, clr)
+}
+
+// drawGrid draws evenly spaced lines across the whole screen. The grid is
+// fixed to the screen, whichever frame the scene is viewed in.
+func (g *Game) drawGrid(screen *ebiten.Image) {
+	var view ebiten.GeoM
+	w, h := float64(g.screenW), float64(g.screenH)
+	for x := g.gridSpacing; x < w; x += g.gridSpacing {
+		drawSegment(screen, view, Vector{X: x, Y: 0}, Vector{X: x, Y: h}, 1, g.theme.Grid)
+	}
+	for y := g.gridSpacing; y < h; y += g.gridSpacing {
+		drawSegment(screen, view, Vector{X: 0, Y: y}, Vector{X: w, Y: y}, 1, g.theme.Grid)
+	}
+}
 
-// getHexagonVertices computes the 6 vertices of the rotating hexagon.
-func (g *Game) getHexagonVertices() []Vector {
-	center := Vector{X: screenWidth / 2, Y: screenHeight / 2}
-	vertices := make([]Vector, 6)
-	for i := 0; i < 6; i++ {
-		angle := g.hexRotation + float64(i)*2*math.Pi/6
-		vertices[i] = Vector{
-			X: center.X + g.hexRadius*math.Cos(angle),
-			Y: center.Y + g.hexRadius*math.Sin(angle),
+// drawScreenWalls draws the screen-edge walls that are on, in the wall
+// color or by wall heat.
+func (g *Game) drawScreenWalls(screen *ebiten.Image, view ebiten.GeoM) {
+	box := g.world.Box
+	topLeft, topRight := box.Min, Vector{X: box.Max.X, Y: box.Min.Y}
+	bottomLeft, bottomRight := Vector{X: box.Min.X, Y: box.Max.Y}, box.Max
+	// In the box's wall order: left, right, top, bottom.
+	sides := [4]struct {
+		on   bool
+		A, B Vector
+	}{
+		{box.Left, topLeft, bottomLeft},
+		{box.Right, topRight, bottomRight},
+		{box.Top, topLeft, topRight},
+		{box.Bottom, bottomLeft, bottomRight},
+	}
+	first := g.world.BoxWalls()
+	for i, side := range sides {
+		if !side.on {
+			continue
+		}
+		clr := g.wallColor
+		if g.enableWallHeat {
+			clr = lerpColor(wallCoolColor, wallHotColor, g.world.WallHeat[first+i])
 		}
+		drawSegment(screen, view, side.A, side.B, g.wallThickness, clr)
 	}
-	return vertices
 }
 
-// closestPointOnSegment returns the point on the line segment AB
-// that is closest to point P.
-func closestPointOnSegment(A, B, P Vector) Vector {
-	AB := B.Sub(A)
-	t := (P.Sub(A)).Dot(AB) / AB.Dot(AB)
-	// Clamp t between 0 and 1.
-	if t < 0 {
-		t = 0
+// drawOutline draws shape at the given rotation, whose walls are numbered
+// from first, in clr or by wall heat. Open walls are left out.
+func (g *Game) drawOutline(screen *ebiten.Image, view ebiten.GeoM, shape physics.Shape, rotation float64, first int, clr color.Color) {
+	edgeColor := func(i int) color.Color {
+		if g.enableWallHeat {
+			return lerpColor(wallCoolColor, wallHotColor, g.world.WallHeat[first+i])
+		}
+		return clr
+	}
+	if circle, ok := shape.(*physics.Circle); ok {
+		cx, cy := view.Apply(circle.Center().X, circle.Center().Y)
+		radius := circle.Radius() * g.cameraScale
+		vector.StrokeCircle(screen, float32(cx), float32(cy), float32(radius), float32(g.wallThickness), edgeColor(0), true)
 	}
-	if t > 1 {
-		t = 1
+	for i, e := range shape.Edges(rotation) {
+		if g.world.OpenWalls[first+i] {
+			continue
+		}
+		drawSegment(screen, view, e[0], e[1], g.wallThickness, edgeColor(i))
 	}
-	return A.Add(AB.Mul(t))
+}
+
+// drawSegment strokes the world-space segment AB onto dst, transformed by
+// view, with the given width in pixels.
+func drawSegment(dst *ebiten.Image, view ebiten.GeoM, A, B Vector, width float64, clr color.Color) {
+	ax, ay := view.Apply(A.X, A.Y)
+	bx, by := view.Apply(B.X, B.Y)
+	vector.StrokeLine(dst, float32(ax), float32(ay), float32(bx), float32(by), float32(width), clr, true)
+}
+
+// viewGeoM returns the transform from world to screen coordinates. In the
+// lab frame it is just the camera; in the rotating frame it first undoes
+// the container's rotation about its center.
+func (g *Game) viewGeoM() ebiten.GeoM {
+	var view ebiten.GeoM
+	if g.rotatingFrame {
+		c := g.world.Container.Center()
+		view.Translate(-c.X, -c.Y)
+		view.Rotate(-g.renderRotation())
+		view.Translate(c.X, c.Y)
+	}
+	view.Concat(g.cameraGeoM())
+	return view
+}
+
+// Layout follows the window size, re-laying out the scene whenever it
+// changes, unless a fixed render size is configured: then the scene keeps
+// that size and Ebiten scales it to the window.
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	if g.cfg.RenderWidth > 0 {
+		return g.screenW, g.screenH
+	}
+	if outsideWidth > 0 && outsideHeight > 0 &&
+		(outsideWidth != g.screenW || outsideHeight != g.screenH) {
+		g.resize(outsideWidth, outsideHeight)
+	}
+	return g.screenW, g.screenH
+}
+
+// resize moves the container by half the change in screen size (keeping
+// centered containers centered) and scales it with the smaller screen
+// dimension. The balls are carried along so they stay inside.
+func (g *Game) resize(w, h int) {
+	scale := math.Min(float64(w), float64(h)) / math.Min(float64(g.screenW), float64(g.screenH))
+	oldCenter := g.world.Container.Center()
+	center := oldCenter.Add(Vector{X: float64(w-g.screenW) / 2, Y: float64(h-g.screenH) / 2})
+	g.world.Container = g.world.Container.Place(center, scale)
+	for _, r := range g.world.Rings {
+		r.Shape = r.Shape.Place(center.Add(r.Shape.Center().Sub(oldCenter).Mul(scale)), scale)
+	}
+	for _, b := range g.world.Balls {
+		b.Pos = center.Add(b.Pos.Sub(oldCenter).Mul(scale))
+		b.Vel = b.Vel.Mul(scale)
+		b.Trail.Clear()
+	}
+	// The old samples no longer line up with the scene.
+	g.heatmap = newHeatmap(w, h)
+	g.dropSnapshot()
+	g.screenW, g.screenH = w, h
+	g.world.Box = g.screenBox()
+}
+
+// screenBox returns the configured screen-edge walls, around the current
+// screen.
+func (g *Game) screenBox() physics.Box {
+	box := physics.Box{Max: Vector{X: float64(g.screenW), Y: float64(g.screenH)}}
+	box.Left = slices.Contains(g.cfg.ScreenWalls, "left")
+	box.Right = slices.Contains(g.cfg.ScreenWalls, "right")
+	box.Top = slices.Contains(g.cfg.ScreenWalls, "top")
+	box.Bottom = slices.Contains(g.cfg.ScreenWalls, "bottom")
+	return box
 }
 
 // ----------------------------------------------------
-// 7. The main function: Run the game.
+// 6. The main function: Run the game.
 // ----------------------------------------------------
 
 func main() {
-	ebiten.SetWindowSize(screenWidth, screenHeight)
+	cfg := DefaultConfig()
+	cfg.RegisterFlags(flag.CommandLine)
+	scriptPath := flag.String("script", "", "JSON file of timed events to play automatically")
+	stats := flag.Bool("stats", false, "print a bounce statistics summary on exit")
+	themeName := flag.String("theme", themes[0].Name, "color theme: "+strings.Join(themeNames(), " or "))
+	recordPath := flag.String("record", "", "record the keyboard and mouse input to this JSON file, for -replay")
+	replayPath := flag.String("replay", "", "play back input recorded with -record (use the same flags)")
+	collisionLogPath := flag.String("collision-log", "", "append every wall collision to this JSONL file")
+	compare := flag.String("compare", "", `split the window and run a second simulation on the right with these flags changed, e.g. "-restitution 0.5"`)
+	flag.Parse()
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if *recordPath != "" && *replayPath != "" {
+		log.Fatal("can't record and replay at the same time")
+	}
+	if *compare != "" && (*recordPath != "" || *replayPath != "") {
+		log.Fatal("can't record or replay a split screen")
+	}
+	if *compare != "" && cfg.RenderWidth > 0 {
+		log.Fatal("a split screen follows the window size; drop -render-width and -render-height")
+	}
+	var recording *inputRecording
+	if *replayPath != "" {
+		var err error
+		if recording, err = loadRecording(*replayPath); err != nil {
+			log.Fatal(err)
+		}
+		cfg.Seed = recording.Seed
+	}
+
+	ebiten.SetWindowSize(cfg.ScreenWidth, cfg.ScreenHeight)
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	// Closing the window goes through Update, like the quit keys.
+	ebiten.SetWindowClosingHandled(true)
 	ebiten.SetWindowTitle("Bouncing Ball in a Spinning Hexagon")
-	game := NewGame()
-	if err := ebiten.RunGame(game); err != nil {
+	theme, ok := themeByName(*themeName)
+	if !ok {
+		log.Fatalf("unknown theme %q (want one of %s)", *themeName, strings.Join(themeNames(), ", "))
+	}
+	// A split screen gives each simulation half the window.
+	leftCfg, rightCfg := cfg, cfg
+	if *compare != "" {
+		var err error
+		if rightCfg, err = compareConfig(cfg, *compare); err != nil {
+			log.Fatalf("-compare: %v", err)
+		}
+		leftCfg.ScreenWidth = cfg.ScreenWidth / 2
+		rightCfg.ScreenWidth = cfg.ScreenWidth - leftCfg.ScreenWidth
+	}
+	game, err := NewGame(leftCfg, theme)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// The sound and the collision log follow the left simulation only.
+	if cfg.Sound {
+		sound, err := newBounceSound()
+		if err != nil {
+			log.Fatal(err)
+		}
+		game.OnCollision = sound.onCollision
+	}
+	if *scriptPath != "" {
+		script, err := loadScript(*scriptPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		game.script = script
+	}
+	if *collisionLogPath != "" {
+		if game.collisionLog, err = createCollisionLog(*collisionLogPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+	switch {
+	case recording != nil:
+		game.replay = &replayer{events: recording.Events}
+		game.lockstep = true
+	case *recordPath != "":
+		game.inputLog = &inputRecording{Seed: cfg.Seed}
+		game.lockstep = true
+	}
+	var run ebiten.Game = game
+	var right *Game
+	if *compare != "" {
+		if right, err = NewGame(rightCfg, theme); err != nil {
+			log.Fatalf("-compare: %v", err)
+		}
+		right.script = game.script
+		run = newSplitScreen(game, right)
+	}
+	if err := ebiten.RunGame(run); err != nil {
 		panic(err)
 	}
+	if game.collisionLog != nil {
+		if err := game.collisionLog.close(); err != nil {
+			log.Fatalf("writing collision log: %v", err)
+		}
+	}
+	if game.inputLog != nil {
+		if err := game.inputLog.save(*recordPath); err != nil {
+			log.Fatalf("saving recording: %v", err)
+		}
+		log.Printf("saved %s", *recordPath)
+	}
+	if *stats {
+		game.printStats(os.Stdout)
+		if right != nil {
+			fmt.Println("Compared with", *compare)
+			right.printStats(os.Stdout)
+		}
+	}
 }
-
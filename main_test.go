@@ -0,0 +1,58 @@
+package main
+
+import (
+	"image/color"
+	"testing"
+)
+
+// TestShadedBall checks that the side of the ball facing the light is
+// brighter than the opposite one, and that the corners stay transparent.
+func TestShadedBall(t *testing.T) {
+	const radius = 16
+	clr := color.RGBA{200, 60, 60, 255}
+	for _, light := range []Vector{{X: -1, Y: -1}, {X: 1, Y: 0}, {X: 0, Y: 1}} {
+		pix := shadedBallPixels(radius, clr, light)
+		// brightness sums the color channels of the pixel at p, an offset
+		// from the ball's center.
+		brightness := func(p Vector) int {
+			x, y := int(radius+p.X), int(radius+p.Y)
+			i := 4 * (y*2*radius + x)
+			return int(pix[i]) + int(pix[i+1]) + int(pix[i+2])
+		}
+		dir := light.Normalize()
+		lit, dark := brightness(dir.Mul(radius*0.5)), brightness(dir.Mul(-radius*0.8))
+		if lit <= dark {
+			t.Errorf("light %v: highlight brightness %d, opposite edge %d", light, lit, dark)
+		}
+		if pix[3] != 0 {
+			t.Errorf("light %v: the corner isn't transparent", light)
+		}
+	}
+}
+
+// TestCachedImage checks that the same key gets the same image back, and
+// different sizes, colors or overlays their own.
+func TestCachedImage(t *testing.T) {
+	g := newTestGame(t)
+	white := color.RGBA{255, 255, 255, 255}
+	key := imageKey{radius: 10, color: white}
+	img := g.cachedImage(key)
+	if again := g.cachedImage(key); again != img {
+		t.Error("the same key rendered a new image")
+	}
+	if w, h := img.Bounds().Dx(), img.Bounds().Dy(); w != 20 || h != 20 {
+		t.Errorf("a radius 10 image is %dx%d, want 20x20", w, h)
+	}
+	for _, other := range []imageKey{
+		{radius: 12, color: white},
+		{radius: 10, color: color.RGBA{255, 0, 0, 255}},
+		{spin: true, radius: 10},
+	} {
+		if g.cachedImage(other) == img {
+			t.Errorf("%+v shares the image of %+v", other, key)
+		}
+	}
+	if len(g.images) != 4 {
+		t.Errorf("%d images cached for 4 keys", len(g.images))
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// ----------------------------------------------------
+// Mouse force.
+// ----------------------------------------------------
+
+// The mouse pulls (left button) or pushes (right button) every ball with
+// an inverse-square force: mouseStrength / d² at distance d, which gives a
+// ball of mass 1 that many px/s², capped at its strength at
+// mouseMinDistance so it stays finite at the cursor.
+const (
+	mouseStrength    = 3e7
+	mouseMinDistance = 30.0
+)
+
+// updateMouse reads the mouse buttons and the cursor position, in world
+// coordinates.
+func (g *Game) updateMouse() {
+	g.mouseSign = 0
+	switch {
+	case g.input.buttonPressed(ebiten.MouseButtonLeft):
+		g.mouseSign = 1
+	case g.input.buttonPressed(ebiten.MouseButtonRight):
+		g.mouseSign = -1
+	}
+	if g.mouseSign == 0 {
+		return
+	}
+	// Undo the view transform so the force acts where the cursor appears.
+	view := g.viewGeoM()
+	view.Invert()
+	wx, wy := view.Apply(g.input.cursor.X, g.input.cursor.Y)
+	g.mousePos = Vector{X: wx, Y: wy}
+}
+
+// mouseAccel is the World's Accel hook: the acceleration the mouse force
+// gives ball b, so heavier balls respond less (a = F/m).
+func (g *Game) mouseAccel(b *Ball) Vector {
+	if g.mouseSign == 0 {
+		return Vector{}
+	}
+	offset := g.mousePos.Sub(b.Pos)
+	d := offset.Len()
+	if d == 0 {
+		return Vector{}
+	}
+	force := offset.Mul(mouseStrength / (d * d * d)).ClampLen(mouseStrength / (mouseMinDistance * mouseMinDistance))
+	return force.Mul(g.mouseSign / b.Mass)
+}
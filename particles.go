@@ -0,0 +1,77 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// ----------------------------------------------------
+// Collision particles.
+// ----------------------------------------------------
+
+const (
+	maxParticles    = 500          // Cap on live particles; extra sparks are dropped.
+	particlesPerHit = 8            // Sparks spawned by one collision.
+	particleLife    = 0.5          // Seconds a spark lives.
+	particleSpread  = math.Pi / 3  // Sparks leave within ±60° of the wall normal.
+	particleRadius  = float32(1.5) // Drawn radius in pixels.
+
+	// A spark's speed is a random base between particleMinSpeed and
+	// particleMaxSpeed (px/s) plus particleHitFactor times the impact
+	// speed, so harder hits throw sparks farther.
+	particleMinSpeed  = 40.0
+	particleMaxSpeed  = 160.0
+	particleHitFactor = 0.3
+)
+
+// Particle is a short-lived spark thrown off by a collision.
+type Particle struct {
+	pos, vel Vector
+	age      float64 // Seconds since it was spawned.
+	color    color.RGBA
+}
+
+// spawnParticles throws sparks from the contact point, fanned out around
+// the wall's inward normal.
+func (g *Game) spawnParticles(contact, normal Vector, impactSpeed float64, clr color.RGBA) {
+	for range particlesPerHit {
+		if len(g.particles) >= maxParticles {
+			return
+		}
+		angle := (g.particleRNG.Float64()*2 - 1) * particleSpread
+		speed := particleMinSpeed + g.particleRNG.Float64()*(particleMaxSpeed-particleMinSpeed) + particleHitFactor*impactSpeed
+		g.particles = append(g.particles, Particle{
+			pos:   contact,
+			vel:   normal.Rotate(angle).Mul(speed),
+			color: clr,
+		})
+	}
+}
+
+// updateParticles moves the sparks and drops the ones that have burned
+// out. They fly in straight lines, unaffected by gravity or walls.
+func (g *Game) updateParticles(dt float64) {
+	live := g.particles[:0]
+	for _, p := range g.particles {
+		p.age += dt
+		if p.age >= particleLife {
+			continue
+		}
+		p.pos = p.pos.Add(p.vel.Mul(dt))
+		live = append(live, p)
+	}
+	g.particles = live
+}
+
+// drawParticles draws the sparks, fading out as they age.
+func (g *Game) drawParticles(screen *ebiten.Image, view ebiten.GeoM) {
+	for _, p := range g.particles {
+		alpha := 1 - p.age/particleLife
+		x, y := view.Apply(p.pos.X, p.pos.Y)
+		clr := color.NRGBA{p.color.R, p.color.G, p.color.B, uint8(255 * alpha)}
+		vector.DrawFilledCircle(screen, float32(x), float32(y), particleRadius, clr, true)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+// TestParticlesKeepRunsReproducible runs the same game with and without
+// sparks: they draw from their own randomness, so balls spawned after
+// the sparks flew still land in the same places.
+func TestParticlesKeepRunsReproducible(t *testing.T) {
+	run := func(particles bool) (*Game, []Vector) {
+		cfg := DefaultConfig()
+		cfg.Particles = particles
+		g, err := NewGame(cfg, themes[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.script = []scriptEvent{{At: 1.5, Action: "spawn", Value: 3}}
+		g.Advance(2)
+		var pos []Vector
+		for _, b := range g.world.Balls {
+			pos = append(pos, b.Pos)
+		}
+		return g, pos
+	}
+	sparked, with := run(true)
+	_, without := run(false)
+	if sparked.collisions == 0 {
+		t.Fatal("no collisions threw sparks")
+	}
+	if len(with) != len(without) {
+		t.Fatalf("%d balls with sparks, %d without", len(with), len(without))
+	}
+	for i := range with {
+		if with[i] != without[i] {
+			t.Errorf("ball %d at %v with sparks, %v without", i, with[i], without[i])
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package physics
+
+import (
+	"errors"
+	"image/color"
+)
+
+// ----------------------------------------------------
+// Balls.
+// ----------------------------------------------------
+
+// Ball is one ball bouncing inside the container. All balls share the
+// World's radius; each has its own mass, motion, heat, color and trail.
+type Ball struct {
+	Pos Vector // Position of the ball.
+	Vel Vector // Velocity of the ball.
+
+	// Mass, always positive. Gravity accelerates every ball alike; mass
+	// matters for forces and for collisions between balls.
+	Mass float64
+
+	// Orientation (radians) and spin (radians per second). Spin comes from
+	// wall friction and feeds back into it.
+	Angle      float64
+	AngularVel float64
+
+	// Heat in [0, 1]: bumped on every bounce (scaled by impact speed) and
+	// cooled each step. Renderers use it to tint the ball.
+	Heat float64
+
+	// Color the ball is drawn in. The physics ignores it.
+	Color color.RGBA
+
+	// Recent positions, drawn as a fading trail; Step appends to it.
+	Trail *Trail
+
+	// Set once the ball has left the container through an open wall.
+	// From then on it flies freely, ignoring all walls.
+	Escaped bool
+
+	// Walls the ball is touching this step and touched in the previous
+	// one, indexed like World.WallHeat, so a resting contact counts as a
+	// single collision.
+	contact, prevContact []bool
+}
+
+// NewBall creates a ball of the given mass at pos moving with vel,
+// remembering up to trailLength positions.
+func NewBall(pos, vel Vector, mass float64, trailLength int) (*Ball, error) {
+	if mass <= 0 {
+		return nil, errors.New("ball mass must be positive")
+	}
+	return &Ball{Pos: pos, Vel: vel, Mass: mass, Trail: NewTrail(trailLength)}, nil
+}
+
+// beginContacts starts a new step's contact tracking for a container
+// with walls walls.
+func (b *Ball) beginContacts(walls int) {
+	if len(b.contact) != walls {
+		b.contact, b.prevContact = make([]bool, walls), make([]bool, walls)
+	}
+	b.contact, b.prevContact = b.prevContact, b.contact
+	for i := range b.contact {
+		b.contact[i] = false
+	}
+}
+
+// ----------------------------------------------------
+// Ball-to-ball collisions.
+// ----------------------------------------------------
+
+// collideBalls resolves collisions between every pair of balls still in
+// the container. A spatial grid limits the candidates to nearby pairs, so
+// the cost grows with the number of balls rather than the number of
+// pairs.
+func (w *World) collideBalls() {
+	if len(w.Balls) < 2 || w.CollisionRadius <= 0 {
+		return
+	}
+	grid := newSpatialGrid(w.Balls, 2*w.CollisionRadius)
+	for i, a := range w.Balls {
+		if a.Escaped {
+			continue
+		}
+		grid.neighbors(i, func(j int) {
+			w.collidePair(a, w.Balls[j])
+		})
+	}
+}
+
+// ballRestitution returns the restitution of collisions between balls:
+// Restitution, as overridden by Mode.
+func (w *World) ballRestitution() float64 {
+	switch w.Mode {
+	case CollisionsElastic:
+		return 1
+	case CollisionsInelastic:
+		return inelasticRestitution
+	}
+	return w.Restitution
+}
+
+// collidePair resolves a collision between balls a and b. The overlap is
+// split in inverse proportion to their masses, and an impulse along the
+// line between their centers exchanges momentum, losing energy like a
+// wall bounce (see ballRestitution).
+func (w *World) collidePair(a, b *Ball) {
+	offset := b.Pos.Sub(a.Pos)
+	dist := offset.Len()
+	if dist >= 2*w.CollisionRadius || dist == 0 {
+		return
+	}
+	normal := offset.Mul(1 / dist) // From a toward b.
+	invA, invB := 1/a.Mass, 1/b.Mass
+	overlap := 2*w.CollisionRadius - dist
+	a.Pos = a.Pos.Sub(normal.Mul(overlap * invA / (invA + invB)))
+	b.Pos = b.Pos.Add(normal.Mul(overlap * invB / (invA + invB)))
+
+	closing := b.Vel.Sub(a.Vel).Dot(normal)
+	if closing >= 0 {
+		return // Already separating.
+	}
+	impulse := -(1 + w.ballRestitution()) * closing / (invA + invB)
+	a.Vel = a.Vel.Sub(normal.Mul(impulse * invA))
+	b.Vel = b.Vel.Add(normal.Mul(impulse * invB))
+}
@@ -0,0 +1,140 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewBallRejectsBadMass(t *testing.T) {
+	for _, mass := range []float64{0, -1} {
+		if _, err := NewBall(Vector{}, Vector{}, mass, 0); err == nil {
+			t.Errorf("NewBall accepted mass %v", mass)
+		}
+	}
+}
+
+// TestCollisionConservesMomentum checks head-on and glancing hits between
+// balls of unequal mass.
+func TestCollisionConservesMomentum(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset Vector // From ball a to ball b.
+	}{
+		{"head-on", Vector{X: 19, Y: 0}},
+		{"glancing", Vector{X: 15, Y: 11}},
+	}
+	for _, tt := range tests {
+		for _, restitution := range []float64{0.5, 1} {
+			w := &World{CollisionRadius: 10, Restitution: restitution}
+			a, _ := NewBall(Vector{X: 100, Y: 100}, Vector{X: 300, Y: 0}, 3, 0)
+			b, _ := NewBall(Vector{X: 100, Y: 100}.Add(tt.offset), Vector{X: -100, Y: 40}, 1, 0)
+			momentum := func() Vector { return a.Vel.Mul(a.Mass).Add(b.Vel.Mul(b.Mass)) }
+			before := momentum()
+
+			w.collidePair(a, b)
+
+			if after := momentum(); !near(after, before) {
+				t.Errorf("%s, restitution %v: momentum went from %v to %v", tt.name, restitution, before, after)
+			}
+			if closing := b.Vel.Sub(a.Vel).Dot(tt.offset.Normalize()); closing < 0 {
+				t.Errorf("%s, restitution %v: balls still closing at %v px/s", tt.name, restitution, -closing)
+			}
+		}
+	}
+}
+
+// TestHeavyBallShoves checks a head-on hit against the textbook result:
+// with restitution 1 a ball three times as heavy keeps half its speed and
+// sends the light one off at one and a half times it.
+func TestHeavyBallShoves(t *testing.T) {
+	w := &World{CollisionRadius: 10, Restitution: 1}
+	heavy, _ := NewBall(Vector{X: 0, Y: 0}, Vector{X: 100, Y: 0}, 3, 0)
+	light, _ := NewBall(Vector{X: 19, Y: 0}, Vector{}, 1, 0)
+	w.collidePair(heavy, light)
+	if math.Abs(heavy.Vel.X-50) > epsilon || math.Abs(light.Vel.X-150) > epsilon {
+		t.Errorf("velocities after the hit: heavy %v, light %v; want 50 and 150", heavy.Vel.X, light.Vel.X)
+	}
+	// The overlap is split in inverse proportion to mass.
+	if math.Abs(heavy.Pos.X+0.25) > epsilon || math.Abs(light.Pos.X-19.75) > epsilon {
+		t.Errorf("positions after the hit: heavy %v, light %v; want -0.25 and 19.75", heavy.Pos.X, light.Pos.X)
+	}
+}
+
+// TestHeat checks that a bounce heats the ball and the wall it hits,
+// harder hits more, and that the heat then dies away.
+func TestHeat(t *testing.T) {
+	heatAfterHit := func(speed float64) (ball, wall float64) {
+		w := &World{CollisionRadius: 10, Restitution: 0.9, GrazingFactor: 1, HeatPerSpeed: 0.001}
+		w.SetContainer(NewRegularPolygon(Vector{X: 400, Y: 300}, 200, 6))
+		b, _ := NewBall(Vector{X: 400, Y: 300}, Vector{X: speed, Y: 0}, 1, 0)
+		w.Balls = []*Ball{b}
+		var edge int
+		w.OnCollision = func(c Collision) { edge = c.Edge }
+		for range 60 {
+			w.Step(1.0 / 60)
+		}
+		return b.Heat, w.WallHeat[edge]
+	}
+	soft, softWall := heatAfterHit(200)
+	hard, hardWall := heatAfterHit(500)
+	if soft <= 0 || softWall <= 0 {
+		t.Fatalf("a hit left the ball at heat %v and the wall at %v", soft, softWall)
+	}
+	if hard <= soft || hardWall <= softWall {
+		t.Errorf("a harder hit heated the ball to %v and the wall to %v, against %v and %v", hard, hardWall, soft, softWall)
+	}
+
+	w := &World{CollisionRadius: 10, HeatDecay: 1.5}
+	w.SetContainer(NewRegularPolygon(Vector{X: 400, Y: 300}, 200, 6))
+	b, _ := NewBall(Vector{X: 400, Y: 300}, Vector{}, 1, 0)
+	b.Heat = 0.8
+	w.Balls = []*Ball{b}
+	for range 60 {
+		w.Step(1.0 / 60)
+	}
+	if want := 0.8 * math.Exp(-1.5); math.Abs(b.Heat-want) > 1e-9 {
+		t.Errorf("after a second idle the heat is %v, want %v", b.Heat, want)
+	}
+	for range 600 {
+		w.Step(1.0 / 60)
+	}
+	if b.Heat > 1e-4 {
+		t.Errorf("heat still %v after ten more seconds", b.Heat)
+	}
+}
+
+// TestGrazingRestitution bounces a ball head-on and at 60° from the
+// normal off a wall: with a grazing factor other than 1, the grazing hit
+// keeps a different share of its normal speed.
+func TestGrazingRestitution(t *testing.T) {
+	normal := Vector{X: 0, Y: -1}
+	// kept returns the share of normal speed a hit at the given angle from
+	// the normal keeps.
+	kept := func(grazing, angle float64) float64 {
+		w := &World{CollisionRadius: 10, Restitution: 0.8, GrazingFactor: grazing}
+		w.SetContainer(NewRegularPolygon(Vector{X: 400, Y: 300}, 200, 6))
+		vel := Vector{X: 0, Y: 100}.Rotate(angle)
+		b, _ := NewBall(Vector{X: 400, Y: 300}, vel, 1, 0)
+		b.beginContacts(len(w.WallHeat))
+		w.bounce(b, 0, normal, Vector{})
+		if math.Abs(b.Vel.X-vel.X) > epsilon {
+			t.Errorf("grazing %g: the frictionless bounce changed the speed along the wall", grazing)
+		}
+		return -b.Vel.Dot(normal) / vel.Dot(normal)
+	}
+	glancing := math.Pi / 3
+	for _, tt := range []struct {
+		grazing, want float64
+	}{
+		{1, 0.8},           // Flat: the angle doesn't matter.
+		{0.5, 0.8 * 2 / 3}, // Two thirds of the way to 0.8*0.5.
+		{1.5, 0.8 * 4 / 3}, // Two thirds of the way to 0.8*1.5.
+	} {
+		if got := kept(tt.grazing, 0); math.Abs(got-0.8) > epsilon {
+			t.Errorf("grazing %g: a head-on hit kept %g of its speed, want 0.8", tt.grazing, got)
+		}
+		if got := kept(tt.grazing, glancing); math.Abs(got-tt.want) > epsilon {
+			t.Errorf("grazing %g: a 60° hit kept %g of its normal speed, want %g", tt.grazing, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package physics
+
+// ----------------------------------------------------
+// Screen-edge walls.
+// ----------------------------------------------------
+
+// Box is a set of straight, axis-aligned walls along the sides of the
+// rectangle from Min to Max, usually the screen. Each side can be turned
+// on separately; Top is the Min.Y side, as y points down on screen.
+type Box struct {
+	Min, Max                 Vector
+	Left, Right, Top, Bottom bool
+}
+
+// boxWalls is the number of walls a box has. They come after the
+// container's and the rings' in WallHeat, as left, right, top, bottom.
+const boxWalls = 4
+
+// BoxWalls returns the index of the box's first wall, its left side.
+func (w *World) BoxWalls() int {
+	return w.RingWalls(len(w.Rings))
+}
+
+// collideBox bounces ball b off the box's walls that are on. The walls
+// don't move, and otherwise they are like any other: same materials,
+// statistics and collision callbacks.
+func (w *World) collideBox(b *Ball) {
+	box, first := w.Box, w.BoxWalls()
+	sides := [boxWalls]struct {
+		on     bool
+		dist   float64 // Distance from the ball's center to the wall.
+		normal Vector
+	}{
+		{box.Left, b.Pos.X - box.Min.X, Vector{X: 1}},
+		{box.Right, box.Max.X - b.Pos.X, Vector{X: -1}},
+		{box.Top, b.Pos.Y - box.Min.Y, Vector{Y: 1}},
+		{box.Bottom, box.Max.Y - b.Pos.Y, Vector{Y: -1}},
+	}
+	for i, s := range sides {
+		if !s.on || s.dist >= w.CollisionRadius {
+			continue
+		}
+		b.Pos = b.Pos.Add(s.normal.Mul(w.CollisionRadius - s.dist))
+		w.bounce(b, first+i, s.normal, Vector{})
+	}
+}
+
+// insideBox reports whether a ball centered at p is clear of the box's
+// walls that are on.
+func (w *World) insideBox(p Vector) bool {
+	r, box := w.CollisionRadius, w.Box
+	return !(box.Left && p.X < box.Min.X+r || box.Right && p.X > box.Max.X-r ||
+		box.Top && p.Y < box.Min.Y+r || box.Bottom && p.Y > box.Max.Y-r)
+}
@@ -0,0 +1,44 @@
+package physics
+
+import "testing"
+
+// TestBoxWithoutContainer checks that with the container gone a falling
+// ball lands on the bottom of the box, reported like any wall collision.
+func TestBoxWithoutContainer(t *testing.T) {
+	w := &World{
+		BallRadius:      10,
+		CollisionRadius: 10,
+		Restitution:     0.5,
+		Gravity:         1000,
+		GravityDir:      Vector{X: 0, Y: 1},
+		GravityOn:       true,
+		NoContainer:     true,
+		Box:             Box{Max: Vector{X: 800, Y: 600}, Bottom: true},
+	}
+	w.SetContainer(NewRegularPolygon(Vector{X: 400, Y: 300}, 100, 6))
+	b, err := NewBall(Vector{X: 400, Y: 300}, Vector{}, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Balls = []*Ball{b}
+	var hits []Collision
+	w.OnCollision = func(c Collision) { hits = append(hits, c) }
+
+	for range 120 {
+		w.Step(1.0 / 60)
+	}
+
+	if len(hits) == 0 {
+		t.Fatal("the ball never hit the bottom of the box")
+	}
+	bottom := w.BoxWalls() + 3
+	if c := hits[0]; c.Edge != bottom || c.Normal != (Vector{X: 0, Y: -1}) {
+		t.Errorf("first hit: edge %d, normal %v; want edge %d, normal (0, -1)", c.Edge, c.Normal, bottom)
+	}
+	if w.Stats.Bounces == 0 || w.Stats.EdgeBounces[bottom] == 0 {
+		t.Errorf("box bounces missing from the statistics: %+v", w.Stats)
+	}
+	if b.Pos.Y > 600-10+1e-9 {
+		t.Errorf("ball sank into the bottom wall: y = %v", b.Pos.Y)
+	}
+}
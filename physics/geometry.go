@@ -0,0 +1,155 @@
+package physics
+
+import "math"
+
+// ----------------------------------------------------
+// Segment collision utilities.
+// ----------------------------------------------------
+
+// closestPointOnSegment returns the point on the line segment AB
+// that is closest to point P. A zero-length segment yields A.
+func closestPointOnSegment(A, B, P Vector) Vector {
+	AB := B.Sub(A)
+	lenSq := AB.Dot(AB)
+	if lenSq == 0 {
+		return A
+	}
+	t := (P.Sub(A)).Dot(AB) / lenSq
+	// Clamp t between 0 and 1.
+	if t < 0 {
+		t = 0
+	}
+	if t > 1 {
+		t = 1
+	}
+	return A.Add(AB.Mul(t))
+}
+
+// inwardNormals returns the unit normal of each of the edges of a closed
+// outline, pointing toward its interior. The side is taken from the
+// outline's winding rather than from a center point, so it is right for
+// every edge of a concave outline too.
+func inwardNormals(edges [][2]Vector) []Vector {
+	// Twice the signed area: positive when the interior lies on the side
+	// Perp turns toward.
+	var area float64
+	for _, e := range edges {
+		area += e[0].Cross(e[1])
+	}
+	sign := 1.0
+	if area < 0 {
+		sign = -1
+	}
+	normals := make([]Vector, len(edges))
+	for i, e := range edges {
+		normals[i] = e[1].Sub(e[0]).Perp().Normalize().Mul(sign)
+	}
+	return normals
+}
+
+// closedCorners reports for each of the edges of a closed outline, with
+// inward normals normals, whether the corner at its start is convex with
+// both walls meeting there closed. skip (if not nil) tells open walls.
+func closedCorners(edges [][2]Vector, normals []Vector, skip func(int) bool) []bool {
+	corners := make([]bool, len(edges))
+	for i, e := range edges {
+		prev := (i + len(edges) - 1) % len(edges)
+		if skip != nil && (skip(i) || skip(prev)) {
+			continue
+		}
+		// The corner is convex when the edge turns toward the interior
+		// of the one before it.
+		corners[i] = e[1].Sub(e[0]).Dot(normals[prev]) > 0
+	}
+	return corners
+}
+
+// signedDistance returns the distance from P to the line through A with
+// unit normal n: positive on the side n points to, negative on the other.
+func signedDistance(P, A, n Vector) float64 {
+	return P.Sub(A).Dot(n)
+}
+
+// sweepEdges finds the first of the edges, with inward normals normals,
+// that a ball of the given radius would cross while its center moves from
+// prev to pos. It only considers edges whose line the center ends up on
+// or beyond and that the ball actually reaches (not just their line), and
+// skips those for which skip (if not nil) returns true, returning the
+// edge index and the fraction of the motion (0..1) at which the ball
+// first touches it, or -1 if no edge is crossed. corners is as returned
+// by closedCorners.
+func sweepEdges(prev, pos Vector, radius float64, edges [][2]Vector, normals []Vector, corners []bool, skip func(int) bool) (int, float64) {
+	hit, first := -1, math.Inf(1)
+	for i, e := range edges {
+		if skip != nil && skip(i) {
+			continue
+		}
+		A, normal := e[0], normals[i]
+		d0 := signedDistance(prev, A, normal)
+		d1 := signedDistance(pos, A, normal)
+		if d1 > 0 || d0 <= d1 {
+			continue
+		}
+		// Solve d0 + (d1-d0)*t = radius for the time of impact.
+		t := math.Max(0, math.Min(1, (d0-radius)/(d0-d1)))
+		// On a concave outline an edge's line runs through the interior,
+		// so make sure the ball touches the edge itself. Just past a
+		// closed convex corner the line still counts: the ball is cutting
+		// the corner.
+		at := prev.Lerp(pos, t)
+		closest, reach := closestPointOnSegment(A, e[1], at), radius+1e-9
+		if closest == A && corners[i] || closest == e[1] && corners[(i+1)%len(edges)] {
+			reach = 2 * radius
+		}
+		if closest.Distance(at) > reach {
+			continue
+		}
+		if t < first {
+			hit, first = i, t
+		}
+	}
+	return hit, first
+}
+
+// sweepPoint returns the fraction of the motion (0..1) at which a ball of
+// the given radius moving from prev to pos first touches point p, or
+// false if it doesn't, or already touched it at prev.
+func sweepPoint(prev, pos, p Vector, radius float64) (float64, bool) {
+	// Solve |prev + t*(pos-prev) - p| = radius for the earliest t.
+	d, f := pos.Sub(prev), prev.Sub(p)
+	a, half, c := d.Dot(d), f.Dot(d), f.Dot(f)-radius*radius
+	if a == 0 || c <= 0 {
+		return 0, false
+	}
+	disc := half*half - a*c
+	if disc < 0 {
+		return 0, false
+	}
+	t := (-half - math.Sqrt(disc)) / a
+	return t, t >= 0 && t <= 1
+}
+
+// PointInPolygon reports whether p lies inside the polygon with the given
+// vertices, in either winding order. Concave polygons work too; points
+// exactly on the outline may count either way.
+func PointInPolygon(p Vector, verts []Vector) bool {
+	return pointInPolygon(p, polygonEdges(verts))
+}
+
+// pointInPolygon reports whether P lies inside the closed outline formed
+// by edges, using the even-odd crossing rule (so it also works for
+// concave outlines).
+func pointInPolygon(P Vector, edges [][2]Vector) bool {
+	inside := false
+	for _, e := range edges {
+		A, B := e[0], e[1]
+		// Count the edges a ray from P toward +X crosses.
+		if (A.Y > P.Y) != (B.Y > P.Y) {
+			x := A.X + (P.Y-A.Y)/(B.Y-A.Y)*(B.X-A.X)
+			if x > P.X {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
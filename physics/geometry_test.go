@@ -0,0 +1,144 @@
+package physics
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestClosestPointOnSegment(t *testing.T) {
+	A, B := Vector{X: 0, Y: 0}, Vector{X: 10, Y: 0}
+	tests := []struct {
+		name    string
+		A, B, P Vector
+		want    Vector
+	}{
+		{"interior", A, B, Vector{X: 4, Y: 3}, Vector{X: 4, Y: 0}},
+		{"on the segment", A, B, Vector{X: 7, Y: 0}, Vector{X: 7, Y: 0}},
+		{"before A (t<0)", A, B, Vector{X: -5, Y: 2}, A},
+		{"past B (t>1)", A, B, Vector{X: 15, Y: -2}, B},
+		{"diagonal", A, Vector{X: 10, Y: 10}, Vector{X: 10, Y: 0}, Vector{X: 5, Y: 5}},
+		{"degenerate", Vector{X: 3, Y: 3}, Vector{X: 3, Y: 3}, Vector{X: 8, Y: -1}, Vector{X: 3, Y: 3}},
+	}
+	for _, tt := range tests {
+		if got := closestPointOnSegment(tt.A, tt.B, tt.P); !near(got, tt.want) {
+			t.Errorf("%s: closestPointOnSegment(%v, %v, %v) = %v, want %v", tt.name, tt.A, tt.B, tt.P, got, tt.want)
+		}
+	}
+}
+
+func TestSignedDistance(t *testing.T) {
+	A, n := Vector{X: 0, Y: 200}, Vector{X: 0, Y: 1} // The inside is below.
+	tests := []struct {
+		name string
+		P    Vector
+		want float64
+	}{
+		{"inside", Vector{X: 50, Y: 230}, 30},
+		{"on the line", Vector{X: -50, Y: 200}, 0},
+		{"outside", Vector{X: 10, Y: 185}, -15},
+	}
+	for _, tt := range tests {
+		if got := signedDistance(tt.P, A, n); math.Abs(got-tt.want) > epsilon {
+			t.Errorf("%s: signedDistance = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestInnerFaceOnly checks that only a ball inside the container bounces
+// off a wall: one whose center is outside has nothing to bounce off.
+func TestInnerFaceOnly(t *testing.T) {
+	square, err := NewPolygon([]Vector{{X: 300, Y: 200}, {X: 500, Y: 200}, {X: 500, Y: 400}, {X: 300, Y: 400}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		y      float64 // Ball center height; the top wall is at y = 200.
+		bounce bool
+	}{
+		{"inside, clear of the wall", 230, false},
+		{"inside, within a radius", 208, true},
+		{"straddling, center inside", 203, true},
+		{"straddling, center outside", 197, false},
+		{"outside", 185, false},
+	}
+	for _, tt := range tests {
+		w := &World{CollisionRadius: 10, Restitution: 1, GrazingFactor: 1}
+		w.SetContainer(square)
+		b, _ := NewBall(Vector{X: 400, Y: tt.y}, Vector{X: 0, Y: -100}, 1, 0)
+		b.beginContacts(len(w.WallHeat))
+		bounced := false
+		w.OnCollision = func(Collision) { bounced = true }
+
+		edges := square.Edges(0)
+		w.collideEdges(b, b.Pos, edges, square.Center(), 0, 0)
+
+		if bounced != tt.bounce {
+			t.Errorf("%s: bounced = %v, want %v", tt.name, bounced, tt.bounce)
+		}
+		if tt.bounce && (math.Abs(b.Pos.Y-210) > epsilon || b.Vel.Y <= 0) {
+			t.Errorf("%s: ball left at y = %v moving %v; want y = 210 moving down", tt.name, b.Pos.Y, b.Vel)
+		}
+		if !tt.bounce && b.Pos.Y != tt.y {
+			t.Errorf("%s: ball moved to y = %v without a bounce", tt.name, b.Pos.Y)
+		}
+	}
+}
+
+// TestPointInPolygon covers a convex square and a concave dart, whose
+// notch ends in the reflex vertex (2, 2), in both windings. Points level
+// with a vertex send the crossing ray straight through it.
+func TestPointInPolygon(t *testing.T) {
+	const tiny = 1e-6
+	square := []Vector{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 0, Y: 2}}
+	dart := []Vector{{X: 0, Y: 0}, {X: 4, Y: 2}, {X: 0, Y: 4}, {X: 2, Y: 2}}
+	tests := []struct {
+		name   string
+		verts  []Vector
+		p      Vector
+		inside bool
+	}{
+		{"square center", square, Vector{X: 1, Y: 1}, true},
+		{"square, right of it", square, Vector{X: 3, Y: 1}, false},
+		{"square, left of it", square, Vector{X: -1, Y: 1}, false},
+		{"square, just inside an edge", square, Vector{X: 2 - tiny, Y: 1}, true},
+		{"square, just outside an edge", square, Vector{X: 2 + tiny, Y: 1}, false},
+		{"square, just inside a corner", square, Vector{X: tiny, Y: tiny}, true},
+		{"square, just outside a corner", square, Vector{X: -tiny, Y: -tiny}, false},
+		{"square, level with a corner", square, Vector{X: 1, Y: 0}, true},
+		{"dart body", dart, Vector{X: 3, Y: 2}, true},
+		{"dart wing", dart, Vector{X: 1, Y: 0.8}, true},
+		{"dart notch", dart, Vector{X: 1, Y: 2}, false},
+		{"dart, beyond its tip", dart, Vector{X: 5, Y: 2}, false},
+		{"dart, just inside its tip", dart, Vector{X: 4 - tiny, Y: 2}, true},
+		{"dart, just outside its tip", dart, Vector{X: 4 + tiny, Y: 2}, false},
+		{"dart, just inside the reflex vertex", dart, Vector{X: 2 + tiny, Y: 2}, true},
+		{"dart, just outside the reflex vertex", dart, Vector{X: 2 - tiny, Y: 2}, false},
+	}
+	for _, tt := range tests {
+		reversed := slices.Clone(tt.verts)
+		slices.Reverse(reversed)
+		for _, verts := range [][]Vector{tt.verts, reversed} {
+			if got := PointInPolygon(tt.p, verts); got != tt.inside {
+				t.Errorf("%s: PointInPolygon(%v, %v) = %v, want %v", tt.name, tt.p, verts, got, tt.inside)
+			}
+		}
+	}
+
+	// A point exactly on an edge may count either way, but on an edge two
+	// polygons share it counts as inside exactly one of them.
+	right := []Vector{{X: 2, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 2}, {X: 2, Y: 2}}
+	below := []Vector{{X: 0, Y: -2}, {X: 2, Y: -2}, {X: 2, Y: 0}, {X: 0, Y: 0}}
+	for _, tt := range []struct {
+		p        Vector
+		neighbor []Vector
+	}{
+		{Vector{X: 2, Y: 1}, right},
+		{Vector{X: 1, Y: 0}, below},
+	} {
+		if PointInPolygon(tt.p, square) == PointInPolygon(tt.p, tt.neighbor) {
+			t.Errorf("%v on a shared edge counts the same for both polygons", tt.p)
+		}
+	}
+}
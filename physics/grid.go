@@ -0,0 +1,56 @@
+package physics
+
+import "math"
+
+// ----------------------------------------------------
+// Spatial grid for ball-to-ball collisions.
+// ----------------------------------------------------
+
+// spatialGrid buckets balls into square cells at least one ball diameter
+// wide, so any two touching balls are in the same or neighboring cells.
+type spatialGrid struct {
+	size  float64
+	cells map[[2]int][]int // Ball indices in each cell, in ascending order.
+	home  [][2]int         // Cell each ball was bucketed into.
+}
+
+// newSpatialGrid buckets balls into cells of the given size, skipping
+// escaped balls.
+func newSpatialGrid(balls []*Ball, size float64) *spatialGrid {
+	g := &spatialGrid{
+		size:  size,
+		cells: make(map[[2]int][]int, len(balls)),
+		home:  make([][2]int, len(balls)),
+	}
+	for i, b := range balls {
+		if b.Escaped {
+			continue
+		}
+		c := g.cell(b.Pos)
+		g.home[i] = c
+		g.cells[c] = append(g.cells[c], i)
+	}
+	return g
+}
+
+// cell returns the coordinates of the cell containing p.
+func (g *spatialGrid) cell(p Vector) [2]int {
+	return [2]int{int(math.Floor(p.X / g.size)), int(math.Floor(p.Y / g.size))}
+}
+
+// neighbors calls fn with the index of every ball after ball i (by
+// index) in ball i's cell and the eight around it, so each nearby pair is
+// visited once and in a fixed order. Cells are the ones the balls were
+// bucketed into, even if resolving an earlier pair has nudged them since.
+func (g *spatialGrid) neighbors(i int, fn func(j int)) {
+	c := g.home[i]
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			for _, j := range g.cells[[2]int{c[0] + dx, c[1] + dy}] {
+				if j > i {
+					fn(j)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package physics
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// scatteredBalls returns n balls of no particular speed at random
+// positions over an 800×600 area, seeded so runs are repeatable.
+func scatteredBalls(n int) []*Ball {
+	rng := rand.New(rand.NewSource(1))
+	balls := make([]*Ball, n)
+	for i := range balls {
+		pos := Vector{X: rng.Float64() * 800, Y: rng.Float64() * 600}
+		balls[i] = &Ball{Pos: pos, Vel: Vector{X: rng.Float64() - 0.5, Y: rng.Float64() - 0.5}.Mul(200), Mass: 1}
+	}
+	return balls
+}
+
+// touchingPairs returns the pairs of balls within 2*radius of each other,
+// found by checking every pair.
+func touchingPairs(balls []*Ball, radius float64) map[[2]int]bool {
+	pairs := map[[2]int]bool{}
+	for i, a := range balls {
+		for j := i + 1; j < len(balls); j++ {
+			if a.Pos.Distance(balls[j].Pos) < 2*radius {
+				pairs[[2]int{i, j}] = true
+			}
+		}
+	}
+	return pairs
+}
+
+// TestGridFindsEveryPair checks the grid against checking every pair.
+func TestGridFindsEveryPair(t *testing.T) {
+	const radius = 10
+	balls := scatteredBalls(500)
+	want := touchingPairs(balls, radius)
+	if len(want) == 0 {
+		t.Fatal("no touching pairs to find")
+	}
+
+	grid := newSpatialGrid(balls, 2*radius)
+	got := map[[2]int]bool{}
+	for i := range balls {
+		grid.neighbors(i, func(j int) {
+			if got[[2]int{i, j}] {
+				t.Errorf("pair (%d, %d) visited twice", i, j)
+			}
+			if balls[i].Pos.Distance(balls[j].Pos) < 2*radius {
+				got[[2]int{i, j}] = true
+			}
+		})
+	}
+	for p := range want {
+		if !got[p] {
+			t.Errorf("grid missed touching pair %v", p)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("grid found %d touching pairs, want %d", len(got), len(want))
+	}
+}
+
+// collideBallsBrute is collideBalls without the grid, checking every
+// pair.
+func (w *World) collideBallsBrute() {
+	for i, a := range w.Balls {
+		for _, b := range w.Balls[i+1:] {
+			w.collidePair(a, b)
+		}
+	}
+}
+
+// BenchmarkCollideBalls compares the grid with checking every pair, for
+// 500 balls.
+func BenchmarkCollideBalls(b *testing.B) {
+	w := &World{Balls: scatteredBalls(500), CollisionRadius: 10, Restitution: 0.9}
+	b.Run("grid", func(b *testing.B) {
+		for range b.N {
+			w.collideBalls()
+		}
+	})
+	b.Run("brute", func(b *testing.B) {
+		for range b.N {
+			w.collideBallsBrute()
+		}
+	})
+}
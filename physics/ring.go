@@ -0,0 +1,142 @@
+package physics
+
+import "math"
+
+// ----------------------------------------------------
+// Nested rings.
+// ----------------------------------------------------
+
+// Ring is an extra wall outline nested inside the container. It spins
+// independently, and balls bounce off whichever face of it they touch:
+// balls inside it are trapped, balls outside it bounce off its outer face.
+type Ring struct {
+	Shape        Shape
+	Rotation     float64 // Current rotation angle (in radians).
+	AngularSpeed float64 // Angular speed (radians per second).
+}
+
+// wallCount returns how many walls shape contributes to World.WallHeat.
+// Curved shapes have no straight edges but still count as one wall.
+func wallCount(shape Shape) int {
+	return max(1, len(shape.Edges(0)))
+}
+
+// SetRings replaces the nested rings, resizing the per-wall state. The
+// rings' walls are numbered after the container's, in ring order.
+func (w *World) SetRings(rings []*Ring) {
+	w.Rings = rings
+	w.resizeWalls()
+}
+
+// resizeWalls sizes WallHeat for the container, the rings and the box.
+func (w *World) resizeWalls() {
+	n := wallCount(w.Container) + boxWalls
+	for _, r := range w.Rings {
+		n += wallCount(r.Shape)
+	}
+	w.WallHeat = make([]float64, n)
+}
+
+// RingWalls returns the index of ring k's first wall.
+func (w *World) RingWalls(k int) int {
+	first := wallCount(w.Container)
+	for _, r := range w.Rings[:k] {
+		first += wallCount(r.Shape)
+	}
+	return first
+}
+
+// collideRing handles collisions of ball b with ring r, whose walls at the
+// current rotation are edges, numbered from first. prevPos is where the
+// ball was at the start of the step; the side of the ring it was on then
+// is the side it bounces off, even if the step carried it across.
+func (w *World) collideRing(b *Ball, r *Ring, prevPos Vector, edges [][2]Vector, first int) {
+	center := r.Shape.Center()
+	if circle, ok := r.Shape.(*Circle); ok {
+		w.collideRingCircle(b, circle, first)
+		return
+	}
+	if pointInPolygon(prevPos, edges) {
+		// Trapped inside the ring, which acts like a container.
+		w.collideEdges(b, prevPos, edges, center, first, r.AngularSpeed)
+		return
+	}
+
+	// Outside the ring, its outer faces are the walls. Like inside, sweep
+	// the step's motion so a fast ball can't cross into the ring: seen
+	// from outside, the normals point away from the ring.
+	open := func(i int) bool { return w.OpenWalls[first+i] }
+	normals := inwardNormals(edges)
+	for i := range normals {
+		normals[i] = normals[i].Mul(-1)
+	}
+	corners := closedCorners(edges, normals, open)
+	sweptEdge, toi := sweepEdges(prevPos, b.Pos, w.CollisionRadius, edges, normals, corners, open)
+	// The corners jutting out of the ring reach the ball before either of
+	// their walls' lines do, so sweep those points too.
+	for i, e := range edges {
+		prev := (i + len(edges) - 1) % len(edges)
+		if open(i) && open(prev) {
+			continue
+		}
+		if t, ok := sweepPoint(prevPos, b.Pos, e[0], w.CollisionRadius); ok && (sweptEdge < 0 || t < toi) {
+			sweptEdge, toi = i, t
+			if open(i) {
+				sweptEdge = prev
+			}
+		}
+	}
+	if sweptEdge >= 0 {
+		b.Pos = prevPos.Lerp(b.Pos, toi)
+	}
+
+	// Otherwise find the deepest contact. The contact can be a corner, so
+	// the normal points from the closest point on the outline to the
+	// ball's center.
+	hit, hitDist := sweptEdge, w.CollisionRadius
+	for i, e := range edges {
+		if open(i) {
+			continue
+		}
+		d := closestPointOnSegment(e[0], e[1], b.Pos).Distance(b.Pos)
+		if d >= w.CollisionRadius || d == 0 {
+			continue
+		}
+		b.contact[first+i] = true
+		if sweptEdge < 0 && d < hitDist {
+			hit, hitDist = i, d
+		}
+	}
+	if hit < 0 {
+		return
+	}
+	closest := closestPointOnSegment(edges[hit][0], edges[hit][1], b.Pos)
+	normal := normals[hit]
+	if offset := b.Pos.Sub(closest); offset.Len() > 0 && !pointInPolygon(b.Pos, edges) {
+		normal = offset.Normalize()
+	}
+	b.Pos = closest.Add(normal.Mul(w.CollisionRadius))
+	wallVel := closest.Sub(center).Perp().Mul(r.AngularSpeed)
+	w.bounce(b, first+hit, normal, wallVel)
+}
+
+// collideRingCircle handles collisions of ball b with a circular ring,
+// from whichever side of it the ball is on. Like the circular container,
+// it doesn't spin.
+func (w *World) collideRingCircle(b *Ball, c *Circle, wall int) {
+	if w.OpenWalls[wall] {
+		return
+	}
+	offset := b.Pos.Sub(c.Center())
+	gap := offset.Len() - c.Radius() // Positive outside the circle.
+	if math.Abs(gap) >= w.CollisionRadius || offset == (Vector{}) {
+		return
+	}
+	// The normal points from the wall toward the ball's side.
+	normal := offset.Normalize()
+	if gap < 0 {
+		normal = normal.Mul(-1)
+	}
+	b.Pos = b.Pos.Add(normal.Mul(w.CollisionRadius - math.Abs(gap)))
+	w.bounce(b, wall, normal, Vector{})
+}
@@ -0,0 +1,45 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestRingSweep fires balls at a square ring fast enough to cross its
+// outline within a step: each must bounce off the face it started on,
+// whether it was heading for a wall or a corner, or trapped inside.
+func TestRingSweep(t *testing.T) {
+	center := Vector{X: 400, Y: 300}
+	tests := []struct {
+		name     string
+		rotation float64 // Of the ring: 0 puts a corner on each axis.
+		pos, vel Vector
+		inside   bool
+	}{
+		{"at a face", math.Pi / 4, Vector{X: 400, Y: 200}, Vector{X: 0, Y: 6000}, false},
+		{"at a corner", 0, Vector{X: 520, Y: 300}, Vector{X: -6000, Y: 0}, false},
+		{"past a corner", 0, Vector{X: 520, Y: 305}, Vector{X: -6000, Y: 0}, false},
+		{"out of it", math.Pi / 4, center, Vector{X: 6000, Y: 0}, true},
+	}
+	for _, tt := range tests {
+		w := newTestWorld(t, 1, []Vector{tt.pos}, []Vector{tt.vel})
+		ring := &Ring{Shape: NewRegularPolygon(center, 60, 4), Rotation: tt.rotation}
+		w.SetRings([]*Ring{ring})
+		var hits []Collision
+		w.OnCollision = func(c Collision) { hits = append(hits, c) }
+		w.Step(1.0 / 60)
+
+		b, edges := w.Balls[0], ring.Shape.Edges(ring.Rotation)
+		if got := pointInPolygon(b.Pos, edges); got != tt.inside {
+			t.Errorf("%s: ball ended at %v, inside the ring = %v", tt.name, b.Pos, got)
+		}
+		if d := minWallDistance(b.Pos, edges); d < w.CollisionRadius-1e-6 {
+			t.Errorf("%s: ball sank %v px into the ring", tt.name, w.CollisionRadius-d)
+		}
+		if len(hits) != 1 || hits[0].Edge < w.RingWalls(0) {
+			t.Errorf("%s: collisions %+v, want one with the ring", tt.name, hits)
+		} else if b.Vel.Dot(tt.vel) >= 0 {
+			t.Errorf("%s: ball still heading on at %v", tt.name, b.Vel)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package physics
+
+import (
+	"errors"
+	"math"
+)
+
+// ----------------------------------------------------
+// Container shapes.
+// ----------------------------------------------------
+
+// Shape is the outline of a container the ball bounces around inside.
+// Shapes are described unrotated; the World passes in its current rotation,
+// which is applied about Center.
+type Shape interface {
+	// Center returns the point the shape rotates about.
+	Center() Vector
+	// Edges returns the wall segments at the given rotation (radians), in
+	// order around the outline.
+	Edges(rotation float64) [][2]Vector
+	// Place returns a copy of the shape moved so its center is at center
+	// and scaled by scale about it.
+	Place(center Vector, scale float64) Shape
+}
+
+// RegularPolygon is a regular polygon whose vertices lie at a fixed
+// distance from its center.
+type RegularPolygon struct {
+	center Vector
+	radius float64 // Distance from the center to a vertex.
+	sides  int
+}
+
+// NewRegularPolygon creates a polygon with the given number of sides,
+// clamped to at least 3.
+func NewRegularPolygon(center Vector, radius float64, sides int) *RegularPolygon {
+	if sides < 3 {
+		sides = 3
+	}
+	return &RegularPolygon{center: center, radius: radius, sides: sides}
+}
+
+// Center implements Shape.
+func (p *RegularPolygon) Center() Vector {
+	return p.center
+}
+
+// Vertices returns the polygon's corners at the given rotation.
+func (p *RegularPolygon) Vertices(rotation float64) []Vector {
+	// Rotate a base vertex around the center, one side's angle at a time.
+	base := Vector{X: p.radius, Y: 0}
+	vertices := make([]Vector, p.sides)
+	for i := range vertices {
+		angle := rotation + float64(i)*2*math.Pi/float64(p.sides)
+		vertices[i] = p.center.Add(base.Rotate(angle))
+	}
+	return vertices
+}
+
+// Edges implements Shape.
+func (p *RegularPolygon) Edges(rotation float64) [][2]Vector {
+	return polygonEdges(p.Vertices(rotation))
+}
+
+// Place implements Shape.
+func (p *RegularPolygon) Place(center Vector, scale float64) Shape {
+	return &RegularPolygon{center: center, radius: p.radius * scale, sides: p.sides}
+}
+
+// Polygon is a container with arbitrary, user-supplied vertices. It
+// rotates about its centroid.
+type Polygon struct {
+	points   []Vector
+	centroid Vector
+}
+
+// NewPolygon creates a polygon from its vertices, listed in order around
+// the outline. At least 3 points are required.
+func NewPolygon(points []Vector) (*Polygon, error) {
+	if len(points) < 3 {
+		return nil, errors.New("polygon needs at least 3 points")
+	}
+	pts := append([]Vector(nil), points...)
+	return &Polygon{points: pts, centroid: centroid(pts)}, nil
+}
+
+// Center implements Shape; it is the polygon's centroid.
+func (p *Polygon) Center() Vector {
+	return p.centroid
+}
+
+// Vertices returns the polygon's corners rotated about the centroid.
+func (p *Polygon) Vertices(rotation float64) []Vector {
+	vertices := make([]Vector, len(p.points))
+	for i, pt := range p.points {
+		vertices[i] = p.centroid.Add(pt.Sub(p.centroid).Rotate(rotation))
+	}
+	return vertices
+}
+
+// Edges implements Shape.
+func (p *Polygon) Edges(rotation float64) [][2]Vector {
+	return polygonEdges(p.Vertices(rotation))
+}
+
+// Place implements Shape.
+func (p *Polygon) Place(center Vector, scale float64) Shape {
+	pts := make([]Vector, len(p.points))
+	for i, pt := range p.points {
+		pts[i] = center.Add(pt.Sub(p.centroid).Mul(scale))
+	}
+	return &Polygon{points: pts, centroid: center}
+}
+
+// centroid returns the area centroid of a simple polygon, falling back to
+// the average of the points when they enclose no area.
+func centroid(points []Vector) Vector {
+	var area float64
+	var c Vector
+	n := len(points)
+	for i, a := range points {
+		b := points[(i+1)%n]
+		cross := a.Cross(b)
+		area += cross
+		c = c.Add(a.Add(b).Mul(cross))
+	}
+	if math.Abs(area) < 1e-9 {
+		var sum Vector
+		for _, p := range points {
+			sum = sum.Add(p)
+		}
+		return sum.Mul(1 / float64(n))
+	}
+	// area holds twice the signed area, so divide by 3*area = 6*A/2.
+	return c.Mul(1 / (3 * area))
+}
+
+// polygonEdges joins consecutive vertices, closing the outline.
+func polygonEdges(vertices []Vector) [][2]Vector {
+	n := len(vertices)
+	edges := make([][2]Vector, n)
+	for i := range vertices {
+		edges[i] = [2]Vector{vertices[i], vertices[(i+1)%n]}
+	}
+	return edges
+}
+
+// Circle is a round container. It has no straight edges, so the World
+// detects collisions with it separately and renderers draw it as a circle.
+type Circle struct {
+	center Vector
+	radius float64
+}
+
+// NewCircle creates a circular container.
+func NewCircle(center Vector, radius float64) *Circle {
+	return &Circle{center: center, radius: radius}
+}
+
+// Center implements Shape.
+func (c *Circle) Center() Vector {
+	return c.center
+}
+
+// Radius returns the circle's radius.
+func (c *Circle) Radius() float64 {
+	return c.radius
+}
+
+// Edges implements Shape; a circle has none.
+func (c *Circle) Edges(rotation float64) [][2]Vector {
+	return nil
+}
+
+// Place implements Shape.
+func (c *Circle) Place(center Vector, scale float64) Shape {
+	return &Circle{center: center, radius: c.radius * scale}
+}
@@ -0,0 +1,41 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestRegularPolygonVertices checks the vertices rotated out of the base
+// one: on the circumcircle, evenly spaced and turned with the rotation.
+func TestRegularPolygonVertices(t *testing.T) {
+	center := Vector{X: 400, Y: 300}
+	hex := NewRegularPolygon(center, 200, 6)
+	h := 200 * math.Sqrt(3) / 2
+	tests := []struct {
+		rotation      float64
+		first, second Vector
+	}{
+		{0, Vector{X: 600, Y: 300}, Vector{X: 500, Y: 300 + h}},
+		{math.Pi / 2, Vector{X: 400, Y: 500}, Vector{X: 400 - h, Y: 400}},
+	}
+	for _, tt := range tests {
+		verts := hex.Vertices(tt.rotation)
+		if len(verts) != 6 {
+			t.Fatalf("rotation %v: %d vertices, want 6", tt.rotation, len(verts))
+		}
+		if !near(verts[0], tt.first) || !near(verts[1], tt.second) {
+			t.Errorf("rotation %v: vertices start %v, %v; want %v, %v", tt.rotation, verts[0], verts[1], tt.first, tt.second)
+		}
+		for i, v := range verts {
+			// A regular hexagon's sides are as long as its radius.
+			next := verts[(i+1)%len(verts)]
+			if math.Abs(v.Distance(center)-200) > epsilon || math.Abs(v.Distance(next)-200) > epsilon {
+				t.Errorf("rotation %v: vertex %d at %v is out of place", tt.rotation, i, v)
+			}
+		}
+	}
+
+	if n := len(NewRegularPolygon(center, 200, 2).Vertices(0)); n != 3 {
+		t.Errorf("a 2-sided polygon has %d vertices, want it clamped to 3", n)
+	}
+}
@@ -0,0 +1,63 @@
+package physics
+
+// ----------------------------------------------------
+// Motion trail.
+// ----------------------------------------------------
+
+// Trail is a fixed-size ring buffer holding a ball's most recent
+// positions, and its speed at each, oldest first.
+type Trail struct {
+	points []Vector
+	speeds []float64
+	next   int // Slot the next point is written to.
+	count  int // Number of valid points.
+}
+
+// NewTrail creates a trail that remembers up to length positions. A
+// length of zero disables the trail.
+func NewTrail(length int) *Trail {
+	if length < 0 {
+		length = 0
+	}
+	return &Trail{points: make([]Vector, length), speeds: make([]float64, length)}
+}
+
+// Push records a new position and the speed there, overwriting the oldest
+// one when full.
+func (t *Trail) Push(p Vector, speed float64) {
+	if len(t.points) == 0 {
+		return
+	}
+	t.points[t.next] = p
+	t.speeds[t.next] = speed
+	t.next = (t.next + 1) % len(t.points)
+	if t.count < len(t.points) {
+		t.count++
+	}
+}
+
+// Len returns the number of positions stored.
+func (t *Trail) Len() int {
+	return t.count
+}
+
+// At returns the i-th stored position, where 0 is the oldest.
+func (t *Trail) At(i int) Vector {
+	return t.points[t.slot(i)]
+}
+
+// SpeedAt returns the speed at the i-th stored position.
+func (t *Trail) SpeedAt(i int) float64 {
+	return t.speeds[t.slot(i)]
+}
+
+// slot returns where the i-th stored position is kept.
+func (t *Trail) slot(i int) int {
+	start := (t.next - t.count + len(t.points)) % len(t.points)
+	return (start + i) % len(t.points)
+}
+
+// Clear forgets all stored positions.
+func (t *Trail) Clear() {
+	t.next, t.count = 0, 0
+}
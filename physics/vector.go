@@ -0,0 +1,83 @@
+package physics
+
+import "math"
+
+// ----------------------------------------------------
+// Vector type.
+// ----------------------------------------------------
+
+// Vector is a simple 2D vector type with helper methods.
+type Vector struct {
+	X, Y float64
+}
+
+// Basic vector operations.
+func (v Vector) Add(u Vector) Vector {
+	return Vector{v.X + u.X, v.Y + u.Y}
+}
+
+func (v Vector) Sub(u Vector) Vector {
+	return Vector{v.X - u.X, v.Y - u.Y}
+}
+
+func (v Vector) Mul(s float64) Vector {
+	return Vector{v.X * s, v.Y * s}
+}
+
+func (v Vector) Dot(u Vector) float64 {
+	return v.X*u.X + v.Y*u.Y
+}
+
+func (v Vector) Len() float64 {
+	return math.Hypot(v.X, v.Y)
+}
+
+// Distance returns the distance between the points v and u.
+func (v Vector) Distance(u Vector) float64 {
+	return v.Sub(u).Len()
+}
+
+func (v Vector) Normalize() Vector {
+	l := v.Len()
+	if l == 0 {
+		return Vector{0, 0}
+	}
+	return Vector{v.X / l, v.Y / l}
+}
+
+// ClampLen returns v scaled down to length max if it is longer, keeping
+// its direction, or v itself otherwise.
+func (v Vector) ClampLen(max float64) Vector {
+	if l := v.Len(); l > max {
+		return v.Mul(max / l)
+	}
+	return v
+}
+
+// Lerp linearly interpolates from v (t = 0) to u (t = 1).
+func (v Vector) Lerp(u Vector, t float64) Vector {
+	return v.Add(u.Sub(v).Mul(t))
+}
+
+// Perp returns a perpendicular vector (rotated 90° counterclockwise).
+func (v Vector) Perp() Vector {
+	return Vector{-v.Y, v.X}
+}
+
+// Rotate returns v rotated counterclockwise by theta radians about the origin.
+func (v Vector) Rotate(theta float64) Vector {
+	sin, cos := math.Sincos(theta)
+	return Vector{v.X*cos - v.Y*sin, v.X*sin + v.Y*cos}
+}
+
+// Reflect returns v mirrored off a surface with unit normal n: the
+// component along n is reversed and the rest kept.
+func (v Vector) Reflect(n Vector) Vector {
+	return v.Sub(n.Mul(2 * v.Dot(n)))
+}
+
+// Cross returns the scalar 2D cross product v × u. It is positive when u
+// lies counterclockwise of v and negative when it lies clockwise.
+func (v Vector) Cross(u Vector) float64 {
+	return v.X*u.Y - v.Y*u.X
+}
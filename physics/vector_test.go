@@ -0,0 +1,129 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+const epsilon = 1e-9
+
+// near reports whether two vectors agree to within epsilon.
+func near(a, b Vector) bool {
+	return math.Abs(a.X-b.X) < epsilon && math.Abs(a.Y-b.Y) < epsilon
+}
+
+func TestVectorOps(t *testing.T) {
+	v, u := Vector{X: 3, Y: 4}, Vector{X: -1, Y: 2}
+	tests := []struct {
+		name      string
+		got, want Vector
+	}{
+		{"Add", v.Add(u), Vector{X: 2, Y: 6}},
+		{"Sub", v.Sub(u), Vector{X: 4, Y: 2}},
+		{"Mul", v.Mul(-0.5), Vector{X: -1.5, Y: -2}},
+		{"Normalize", v.Normalize(), Vector{X: 0.6, Y: 0.8}},
+		{"Normalize zero", Vector{}.Normalize(), Vector{}},
+		{"Perp", v.Perp(), Vector{X: -4, Y: 3}},
+		{"Lerp 0", v.Lerp(u, 0), v},
+		{"Lerp 1", v.Lerp(u, 1), u},
+		{"Lerp 0.5", v.Lerp(u, 0.5), Vector{X: 1, Y: 3}},
+		{"Lerp 0.25", v.Lerp(u, 0.25), Vector{X: 2, Y: 3.5}},
+		{"Lerp past the end", v.Lerp(u, 2), Vector{X: -5, Y: 0}},
+		{"Lerp same points", u.Lerp(u, 0.7), u},
+		{"ClampLen over", v.ClampLen(2.5), Vector{X: 1.5, Y: 2}},
+		{"ClampLen under", v.ClampLen(10), v},
+		{"ClampLen at the limit", v.ClampLen(5), v},
+		{"ClampLen zero", Vector{}.ClampLen(1), Vector{}},
+		{"ClampLen to zero", v.ClampLen(0), Vector{}},
+		{"Rotate 90°", Vector{X: 1, Y: 0}.Rotate(math.Pi / 2), Vector{X: 0, Y: 1}},
+		{"Rotate 180°", v.Rotate(math.Pi), Vector{X: -3, Y: -4}},
+		{"Reflect head-on", Vector{X: 0, Y: -5}.Reflect(Vector{X: 0, Y: 1}), Vector{X: 0, Y: 5}},
+		{"Reflect off a vertical wall", Vector{X: -4, Y: 1}.Reflect(Vector{X: 1, Y: 0}), Vector{X: 4, Y: 1}},
+		{"Reflect off the far side", Vector{X: 4, Y: 1}.Reflect(Vector{X: -1, Y: 0}), Vector{X: -4, Y: 1}},
+		{"Reflect along the wall", Vector{X: 0, Y: 3}.Reflect(Vector{X: 1, Y: 0}), Vector{X: 0, Y: 3}},
+		{"Reflect oblique", Vector{X: 2, Y: -3}.Reflect(Vector{X: 0, Y: 1}), Vector{X: 2, Y: 3}},
+		{"Reflect diagonal", Vector{X: 1, Y: 0}.Reflect(Vector{X: -1, Y: 1}.Normalize()), Vector{X: 0, Y: 1}},
+	}
+	for _, tt := range tests {
+		if !near(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestVectorScalars(t *testing.T) {
+	v, u := Vector{X: 3, Y: 4}, Vector{X: -1, Y: 2}
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"Dot", v.Dot(u), 5},
+		{"Dot perpendicular", v.Dot(v.Perp()), 0},
+		{"Len", v.Len(), 5},
+		{"Len zero", Vector{}.Len(), 0},
+		{"Distance", v.Distance(u), math.Sqrt(20)},
+		{"Lerp covers its share", v.Distance(v.Lerp(u, 0.3)), 0.3 * v.Distance(u)},
+		{"Distance swapped", u.Distance(v), math.Sqrt(20)},
+		{"Distance to itself", v.Distance(v), 0},
+		{"Distance axis", Vector{X: -2, Y: 7}.Distance(Vector{X: 5, Y: 7}), 7},
+		{"Cross", v.Cross(u), 10},
+		{"Cross swapped", u.Cross(v), -10},
+		{"Cross parallel", v.Cross(v.Mul(2)), 0},
+		{"Normalize length", u.Normalize().Len(), 1},
+		{"Rotate keeps length", u.Rotate(1).Len(), u.Len()},
+		{"Reflect keeps length", u.Reflect(Vector{X: 0.6, Y: 0.8}).Len(), u.Len()},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > epsilon {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// sign returns -1, 0 or 1 as x is negative, zero or positive.
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+// TestCross checks the cross product's sign tells which side of an edge
+// a point is on, as the winding of an outline relies on.
+func TestCross(t *testing.T) {
+	a, b := Vector{X: 0, Y: 0}, Vector{X: 4, Y: 0}
+	edge := b.Sub(a)
+	for _, tt := range []struct {
+		p    Vector
+		want float64 // Sign of the cross product.
+	}{
+		{Vector{X: 2, Y: 3}, 1},   // Counterclockwise of the edge.
+		{Vector{X: 2, Y: -3}, -1}, // Clockwise.
+		{Vector{X: 9, Y: 0}, 0},   // On its line.
+	} {
+		got := edge.Cross(tt.p.Sub(a))
+		if sign(got) != tt.want {
+			t.Errorf("point %v: cross %v, want sign %v", tt.p, got, tt.want)
+		}
+		// The cross product is the dot product with the perpendicular.
+		if p := edge.Perp().Dot(tt.p.Sub(a)); math.Abs(got-p) > epsilon {
+			t.Errorf("point %v: cross %v but perp dot %v", tt.p, got, p)
+		}
+	}
+
+	// Both windings of a square get normals pointing inward.
+	square := []Vector{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 0, Y: 2}}
+	reversed := []Vector{square[3], square[2], square[1], square[0]}
+	for _, verts := range [][]Vector{square, reversed} {
+		edges := polygonEdges(verts)
+		for i, n := range inwardNormals(edges) {
+			mid := edges[i][0].Lerp(edges[i][1], 0.5)
+			if n.Dot(Vector{X: 1, Y: 1}.Sub(mid)) <= 0 {
+				t.Errorf("%v: edge %d's normal %v points out", verts, i, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,728 @@
+// Package physics simulates balls bouncing inside a rotating container
+// under gravity, drag and wall friction. It has no rendering or input
+// dependencies, so it can run headless in tests and benchmarks.
+package physics
+
+import "math"
+
+// ----------------------------------------------------
+// The simulated world.
+// ----------------------------------------------------
+
+// World holds the balls, the container and the physics parameters. The
+// fields can be changed freely between steps.
+type World struct {
+	// The balls and the radius they share.
+	Balls      []*Ball
+	BallRadius float64
+	// Whether balls collide with each other as well as with the walls.
+	BallCollisions bool
+	// Radius used by the physics; defaults to BallRadius but can be made
+	// larger or smaller to tune how tight collisions feel.
+	CollisionRadius float64
+
+	// Container properties. The shape is fixed (see SetContainer); the
+	// rotation and its speed are simulation state.
+	Container    Shape
+	Rotation     float64 // Current rotation angle (in radians).
+	AngularSpeed float64 // Angular speed (radians per second).
+	// Whether the container's walls are gone, leaving the balls to the
+	// rings and the box. The container still gives the scene its center.
+	NoContainer bool
+
+	// Rings nested inside the container, each spinning on its own (see
+	// SetRings).
+	Rings []*Ring
+
+	// Straight walls along the screen edges, which every ball bounces off
+	// besides the container's, escaped balls included. Their walls come
+	// last in WallHeat (see BoxWalls).
+	Box Box
+
+	// Open walls, by index as in WallHeat: balls pass straight through
+	// them. A ball leaving the container through one escapes.
+	OpenWalls map[int]bool
+
+	// Physics parameters.
+	Gravity     float64 // Gravity strength (pixels per second²).
+	GravityDir  Vector  // Unit vector gravity pulls along ({0, 1} is down).
+	GravityOn   bool    // Turns gravity off without losing its value.
+	Restitution float64 // Fraction of normal speed kept on a head-on bounce.
+	Drag        float64 // Air drag rate: velocity decays as exp(-Drag*t).
+	Friction    float64 // Coulomb friction coefficient between ball and walls.
+	// Restitution multiplier for a fully grazing hit; the effective value
+	// is interpolated by angle of incidence. 1 keeps it angle-independent.
+	GrazingFactor float64
+	// RestitutionFn, if set, scales the restitution of a hit by a factor
+	// depending on the impact speed (px/s), so materials can be softer or
+	// bouncier at high speeds.
+	RestitutionFn func(speed float64) float64
+	// Per-wall materials, by index as in WallHeat. Walls without an entry
+	// use Restitution and Friction.
+	Materials map[int]Material
+	// Mode can override all of the above with perfectly elastic or very
+	// inelastic collisions, walls and balls alike.
+	Mode CollisionMode
+	// Speed limit (px/s) applied at the end of every step, keeping the
+	// direction of motion; 0 disables it.
+	MaxSpeed float64
+	// Accel, if set, returns an extra acceleration (px/s²) on ball b,
+	// applied along with gravity.
+	Accel func(b *Ball) Vector
+	// Field, if set, is a force field: the acceleration (px/s²) at
+	// position pos and simulated time t, applied along with gravity.
+	Field func(pos Vector, t float64) Vector
+
+	// Simulated seconds since the world was created (or Time reset).
+	Time float64
+
+	// Heat: how fast balls and walls heat up (per pixel/second of impact
+	// speed, saturating at 1) and cool down (fraction lost per second,
+	// exponential).
+	HeatPerSpeed  float64
+	HeatDecay     float64
+	WallHeat      []float64 // One entry per wall, container first, in [0, 1].
+	WallHeatDecay float64
+
+	// Bounce statistics since the world was created (or Stats reset).
+	Stats Stats
+
+	// OnCollision, if set, is called for every wall collision Step
+	// resolves. A ball resting against a wall only triggers it when the
+	// contact begins.
+	OnCollision func(Collision)
+
+	// OnEnergyGain, if set, is called when a ball's bounces in one step
+	// raised its energy (see Energy) by more than EnergyTolerance.
+	OnEnergyGain func(b *Ball, before, after float64)
+
+	// OnExit, if set, is called when a ball leaves the container through
+	// the open wall with index wall.
+	OnExit func(b *Ball, wall int)
+
+	// OnEscape, if set, is called when the safety net finds a ball
+	// outside the container (at position from) and pulls it back in.
+	// That should never happen, so it points at a physics bug.
+	OnEscape func(b *Ball, from Vector)
+}
+
+// Material describes how a wall responds to a hit.
+type Material struct {
+	Restitution float64 // Fraction of normal speed kept on a head-on bounce.
+	Friction    float64 // Coulomb friction coefficient.
+}
+
+// CollisionMode selects how collisions lose energy.
+type CollisionMode int
+
+const (
+	// CollisionsNormal uses the configured restitution and friction.
+	CollisionsNormal CollisionMode = iota
+	// CollisionsElastic makes every collision perfectly elastic and
+	// frictionless, so without gravity, drag or moving walls the energy
+	// is conserved.
+	CollisionsElastic
+	// CollisionsInelastic makes every collision soft and grippy.
+	CollisionsInelastic
+)
+
+// Restitution and friction of every collision in CollisionsInelastic.
+const (
+	inelasticRestitution = 0.3
+	inelasticFriction    = 0.8
+)
+
+// String returns the mode's name.
+func (m CollisionMode) String() string {
+	switch m {
+	case CollisionsElastic:
+		return "elastic"
+	case CollisionsInelastic:
+		return "inelastic"
+	}
+	return "normal"
+}
+
+// Collision describes a resolved wall collision.
+type Collision struct {
+	Ball   *Ball
+	Edge   int    // Index of the wall, as in WallHeat.
+	Point  Vector // Contact point on the wall.
+	Normal Vector // The wall's inward unit normal.
+	// The ball's speed into the wall, relative to the moving wall.
+	ImpactSpeed float64
+	// The ball's velocity before the bounce; Ball.Vel is the one after.
+	VelBefore Vector
+}
+
+// Stats accumulates bounce statistics.
+type Stats struct {
+	Bounces     int
+	EdgeBounces []int   // Bounce count per wall.
+	ImpactSum   float64 // Sum of impact speeds, for the average.
+	ImpactMax   float64
+	Distance    float64 // Total distance traveled by all balls.
+}
+
+// recordBounce counts a bounce against the given edge.
+func (s *Stats) recordBounce(edge int, impactSpeed float64) {
+	for len(s.EdgeBounces) <= edge {
+		s.EdgeBounces = append(s.EdgeBounces, 0)
+	}
+	s.Bounces++
+	s.EdgeBounces[edge]++
+	s.ImpactSum += impactSpeed
+	if impactSpeed > s.ImpactMax {
+		s.ImpactMax = impactSpeed
+	}
+}
+
+// EnergyTolerance is the relative energy gain across a bounce that the
+// energy check still accepts, leaving room for the position correction
+// out of the wall.
+const EnergyTolerance = 0.01
+
+// After resolving a contact, the ball is pushed out of any other walls it
+// overlaps by more than penetrationSlop pixels, over at most cornerPasses
+// passes (a corner's two walls can push it back and forth).
+const (
+	penetrationSlop = 1e-6
+	cornerPasses    = 4
+)
+
+// SetContainer replaces the container shape, resizing the per-wall state.
+func (w *World) SetContainer(shape Shape) {
+	w.Container = shape
+	w.resizeWalls()
+}
+
+// Material returns the material of wall number wall, as overridden by
+// Mode.
+func (w *World) Material(wall int) Material {
+	switch w.Mode {
+	case CollisionsElastic:
+		return Material{Restitution: 1}
+	case CollisionsInelastic:
+		return Material{Restitution: inelasticRestitution, Friction: inelasticFriction}
+	}
+	if m, ok := w.Materials[wall]; ok {
+		return m
+	}
+	return Material{Restitution: w.Restitution, Friction: w.Friction}
+}
+
+// CurrentGravity returns the gravity in effect, which is zero while
+// gravity is turned off.
+func (w *World) CurrentGravity() float64 {
+	if !w.GravityOn {
+		return 0
+	}
+	return w.Gravity
+}
+
+// Energy returns ball b's mechanical energy per unit mass: kinetic
+// energy of its motion and spin plus gravitational potential relative to
+// the container center.
+func (w *World) Energy(b *Ball) float64 {
+	center := w.Container.Center()
+	// A solid disk's moment of inertia is radius²/2 per unit mass.
+	inertia := 0.5 * w.CollisionRadius * w.CollisionRadius
+	kinetic := 0.5*b.Vel.Dot(b.Vel) + 0.5*inertia*b.AngularVel*b.AngularVel
+	// Potential falls as the ball moves along the gravity direction.
+	potential := -w.CurrentGravity() * b.Pos.Sub(center).Dot(w.GravityDir)
+	return kinetic + potential
+}
+
+// TotalEnergy returns the balls' total mechanical energy: their Energy
+// weighted by mass.
+func (w *World) TotalEnergy() float64 {
+	var sum float64
+	for _, b := range w.Balls {
+		sum += b.Mass * w.Energy(b)
+	}
+	return sum
+}
+
+// FitsInside reports whether a ball centered at p lies inside the
+// container without touching its walls, any ring's or the box's, with
+// everything turned as far as it will have rotated ahead seconds from
+// now.
+func (w *World) FitsInside(p Vector, ahead float64) bool {
+	if !w.insideBox(p) {
+		return false
+	}
+	switch circle, ok := w.Container.(*Circle); {
+	case w.NoContainer:
+		// Only the rings and the box are left.
+	case ok:
+		if p.Distance(circle.Center())+w.CollisionRadius >= circle.Radius() {
+			return false
+		}
+	default:
+		edges := w.Container.Edges(w.Rotation + w.AngularSpeed*ahead)
+		if !pointInPolygon(p, edges) {
+			return false
+		}
+		for _, e := range edges {
+			if closestPointOnSegment(e[0], e[1], p).Distance(p) < w.CollisionRadius {
+				return false
+			}
+		}
+	}
+	for _, r := range w.Rings {
+		if circle, ok := r.Shape.(*Circle); ok {
+			if math.Abs(p.Distance(circle.Center())-circle.Radius()) < w.CollisionRadius {
+				return false
+			}
+			continue
+		}
+		for _, e := range r.Shape.Edges(r.Rotation + r.AngularSpeed*ahead) {
+			if closestPointOnSegment(e[0], e[1], p).Distance(p) < w.CollisionRadius {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Predict integrates a copy of ball b's motion forward by up to steps
+// steps of dt seconds under gravity and drag, ignoring collisions, and
+// returns the predicted positions. It stops at the first position where
+// the ball would touch a wall, with the walls rotated as far as they will
+// have turned by then.
+func (w *World) Predict(b *Ball, steps int, dt float64) []Vector {
+	pos, vel := b.Pos, b.Vel
+	var points []Vector
+	for i := range steps {
+		vel = vel.Add(w.GravityDir.Mul(w.CurrentGravity() * dt))
+		vel = vel.Mul(math.Exp(-w.Drag * dt))
+		pos = pos.Add(vel.Mul(dt))
+		if !w.FitsInside(pos, float64(i+1)*dt) {
+			break
+		}
+		points = append(points, pos)
+	}
+	return points
+}
+
+// ----------------------------------------------------
+// Stepping: Physics and collision handling.
+// ----------------------------------------------------
+
+// Step advances the world by dt seconds: collisions between balls, wall
+// rotation, then for every ball gravity, drag, integration and wall
+// collision handling.
+func (w *World) Step(dt float64) {
+	// Separate touching balls first, so the wall handling below gets the
+	// last word on where each ball ends up. The push counts as part of the
+	// step's motion, so a ball pushed against a wall is swept into it like
+	// any other.
+	starts := make([]Vector, len(w.Balls))
+	for i, b := range w.Balls {
+		starts[i] = b.Pos
+	}
+	if w.BallCollisions {
+		w.collideBalls()
+	}
+
+	// Let the walls cool down a little.
+	for i := range w.WallHeat {
+		w.WallHeat[i] *= math.Exp(-w.WallHeatDecay * dt)
+	}
+
+	w.Time += dt
+
+	// Update the container’s and the rings' rotation.
+	w.Rotation += w.AngularSpeed * dt
+	for _, r := range w.Rings {
+		r.Rotation += r.AngularSpeed * dt
+	}
+
+	edges := w.Container.Edges(w.Rotation)
+	ringEdges := make([][][2]Vector, len(w.Rings))
+	for k, r := range w.Rings {
+		ringEdges[k] = r.Shape.Edges(r.Rotation)
+	}
+	for i, b := range w.Balls {
+		w.stepBall(b, dt, starts[i], edges, ringEdges)
+	}
+}
+
+// stepBall moves one ball, which started the step at start, through a
+// step of dt seconds and resolves its collisions with the container and
+// the rings, whose walls are at edges and ringEdges.
+func (w *World) stepBall(b *Ball, dt float64, start Vector, edges [][2]Vector, ringEdges [][][2]Vector) {
+	// Apply gravity to the ball along the gravity direction, plus any
+	// extra acceleration and the force field.
+	b.Vel = b.Vel.Add(w.GravityDir.Mul(w.CurrentGravity() * dt))
+	if w.Accel != nil {
+		b.Vel = b.Vel.Add(w.Accel(b).Mul(dt))
+	}
+	if w.Field != nil {
+		b.Vel = b.Vel.Add(w.Field(b.Pos, w.Time).Mul(dt))
+	}
+
+	// Apply a little air friction (damping) to slow the ball over time.
+	// Exponential decay keeps the loss per second independent of dt.
+	b.Vel = b.Vel.Mul(math.Exp(-w.Drag * dt))
+
+	// Update the ball's position. prevPos is where it started the step,
+	// before any push from other balls.
+	prevPos := start
+	b.Pos = b.Pos.Add(b.Vel.Mul(dt))
+
+	// Turn the ball by its spin.
+	b.Angle += b.AngularVel * dt
+
+	// Let the ball cool down a little.
+	b.Heat *= math.Exp(-w.HeatDecay * dt)
+
+	b.beginContacts(len(w.WallHeat))
+	w.collideBox(b)
+	if b.Escaped {
+		b.Trail.Push(b.Pos, b.Vel.Len())
+		w.Stats.Distance += prevPos.Distance(b.Pos)
+		return
+	}
+
+	// Note the energy and bounce count going into collision handling so
+	// the energy check can tell whether a bounce added energy.
+	var energyBefore float64
+	if w.OnEnergyGain != nil {
+		energyBefore = w.Energy(b)
+	}
+	bouncesBefore := w.Stats.Bounces
+
+	// Detect and resolve collisions with the container's and the rings'
+	// walls.
+	switch circle, ok := w.Container.(*Circle); {
+	case w.NoContainer:
+		// Only the rings and the box are left.
+	case ok:
+		w.collideCircle(b, circle)
+	default:
+		w.collideEdges(b, prevPos, edges, w.Container.Center(), 0, w.AngularSpeed)
+	}
+	for k, r := range w.Rings {
+		w.collideRing(b, r, prevPos, ringEdges[k], w.RingWalls(k))
+	}
+
+	if w.OnEnergyGain != nil && w.Stats.Bounces > bouncesBefore {
+		if after := w.Energy(b); after-energyBefore > EnergyTolerance*math.Abs(energyBefore) {
+			w.OnEnergyGain(b, energyBefore, after)
+		}
+	}
+
+	if !w.NoContainer {
+		w.keepInside(b, edges)
+	}
+
+	// Cap runaway speeds from compounding bounces off the moving walls.
+	if w.MaxSpeed > 0 {
+		b.Vel = b.Vel.ClampLen(w.MaxSpeed)
+	}
+
+	// Remember where the ball ended up for the trail and the statistics.
+	b.Trail.Push(b.Pos, b.Vel.Len())
+	w.Stats.Distance += prevPos.Distance(b.Pos)
+}
+
+// collideEdges handles collisions of ball b against the straight walls of
+// an outline it is inside of, which may be concave. prevPos is where the
+// ball was at the start of the step, center is the point the walls rotate
+// about at angularSpeed, and first is the index of the first wall.
+func (w *World) collideEdges(b *Ball, prevPos Vector, edges [][2]Vector, center Vector, first int, angularSpeed float64) {
+	normals := inwardNormals(edges)
+
+	// Continuous collision: a fast ball can cross a wall within a single
+	// frame, which the sampled checks below would miss. Sweep the frame's
+	// motion and, if it crosses an edge, rewind the ball to the moment it
+	// first touched that edge and resolve the collision there.
+	open := func(i int) bool { return w.OpenWalls[first+i] }
+	corners := closedCorners(edges, normals, open)
+	sweptEdge, toi := sweepEdges(prevPos, b.Pos, w.CollisionRadius, edges, normals, corners, open)
+	if sweptEdge >= 0 {
+		b.Pos = prevPos.Lerp(b.Pos, toi)
+	}
+
+	// The ball can only hit the inner face of a wall, so first make sure
+	// its center is inside the container. From outside there is nothing
+	// to bounce off.
+	inside := pointInPolygon(b.Pos, edges)
+
+	// Resolve a single contact per step: the swept edge if the ball
+	// crossed one (it was hit first), otherwise the deepest penetrating
+	// edge. Resolving every touching edge in turn would test later edges
+	// against a position already corrected for earlier ones, which near a
+	// corner yanks the ball or reflects it twice. A second touching edge
+	// is handled on the next step.
+	//
+	// Every wall the ball touches still counts as in contact, so a ball
+	// wedged in a corner doesn't register a fresh collision each time the
+	// resolution alternates between the two walls.
+	hit, hitDist := sweptEdge, w.CollisionRadius
+	if inside {
+		for i, e := range edges {
+			if open(i) {
+				continue
+			}
+			// The ball’s distance to this edge. It is measured to the
+			// segment, not its line, which on a concave outline can pass
+			// through the interior.
+			dist := closestPointOnSegment(e[0], e[1], b.Pos).Distance(b.Pos)
+			if dist >= w.CollisionRadius {
+				continue
+			}
+			b.contact[first+i] = true
+			if sweptEdge < 0 && dist < hitDist {
+				hit, hitDist = i, dist
+			}
+		}
+	}
+	if hit < 0 {
+		return
+	}
+
+	// --- Collision detected ---
+	// The contact is the point on the edge closest to the ball’s center.
+	contact := func(i int) (Vector, Vector) {
+		return edgeContact(b.Pos, edges[i], normals[i], corners[i], corners[(i+1)%len(edges)])
+	}
+	closest, normal := contact(hit)
+
+	// Correct the ball's position so it's no longer penetrating the wall.
+	b.Pos = closest.Add(normal.Mul(w.CollisionRadius))
+
+	// In a corner that can leave the ball sunk into the neighboring wall.
+	// Only push it back out of that one: it bounces off it on the next
+	// step, still in contact. Pushing it straight off one wall and then the
+	// other barely gets it out of an acute corner, so it goes where it
+	// clears both the wall it was last held off and this one.
+	last := hit
+	for range cornerPasses {
+		moved := false
+		for i := range edges {
+			if open(i) {
+				continue
+			}
+			c, n := contact(i)
+			if c.Distance(b.Pos) >= w.CollisionRadius-penetrationSlop {
+				continue
+			}
+			lc, ln := contact(last)
+			if p, ok := clearOfBoth(c, n, lc, ln, w.CollisionRadius); ok && i != last && p.Distance(b.Pos) <= w.CollisionRadius {
+				b.Pos = p
+			} else {
+				b.Pos = c.Add(n.Mul(w.CollisionRadius))
+			}
+			last, moved = i, true
+		}
+		if !moved {
+			break
+		}
+	}
+
+	// To simulate a "realistic" collision with a moving wall, we
+	// compute the wall’s velocity at the collision point.
+	r := closest.Sub(center)
+	// For a rotating body, the velocity at point r is omega × r.
+	// In 2D, this gives: wallVel = omega * (-r.Y, r.X)
+	wallVel := r.Perp().Mul(angularSpeed)
+
+	w.bounce(b, first+hit, normal, wallVel)
+}
+
+// edgeContact returns the point on edge e closest to a ball centered at p
+// and the normal pushing the ball off it. Along the edge that is the
+// edge's own inward normal; at an end of it (the tip of a concave corner)
+// it points from the corner to the ball. At an end where the edge meets
+// its neighbor in a closed convex corner (startCorner, endCorner) the
+// ball is held off the edge's line instead: pushing it away from the
+// corner point would only drive it into the neighbor.
+func edgeContact(p Vector, e [2]Vector, normal Vector, startCorner, endCorner bool) (closest, n Vector) {
+	closest = closestPointOnSegment(e[0], e[1], p)
+	if closest == e[0] && startCorner || closest == e[1] && endCorner {
+		return p.Sub(normal.Mul(signedDistance(p, e[0], normal))), normal
+	}
+	if offset := p.Sub(closest); offset.Dot(normal) > 0 && offset.Len() > 0 {
+		return closest, offset.Normalize()
+	}
+	return closest, normal
+}
+
+// clearOfBoth returns the point radius away from both the line through
+// a with unit normal na and the one through b with unit normal nb, on the
+// side the normals point to. It reports false for parallel lines.
+func clearOfBoth(a, na, b, nb Vector, radius float64) (Vector, bool) {
+	det := na.Cross(nb)
+	if math.Abs(det) < 1e-9 {
+		return Vector{}, false
+	}
+	// Solve na·p = na·a + radius and nb·p = nb·b + radius.
+	da, db := na.Dot(a)+radius, nb.Dot(b)+radius
+	return Vector{X: (da*nb.Y - db*na.Y) / det, Y: (na.X*db - nb.X*da) / det}, true
+}
+
+// collideCircle handles collisions of ball b against a circular wall. The
+// circle does not spin, so its wall velocity is zero.
+func (w *World) collideCircle(b *Ball, c *Circle) {
+	if w.OpenWalls[0] {
+		return
+	}
+	offset := b.Pos.Sub(c.Center())
+	dist := c.Radius() - offset.Len() // Signed distance, positive inside.
+	if dist >= w.CollisionRadius || offset == (Vector{}) {
+		return
+	}
+	// The inward normal points from the wall back toward the center.
+	normal := offset.Normalize().Mul(-1)
+	b.Pos = b.Pos.Add(normal.Mul(w.CollisionRadius - dist))
+	w.bounce(b, 0, normal, Vector{})
+}
+
+// bounce resolves ball b hitting wall number edge, whose inward normal is
+// normal and which moves with velocity wallVel at the contact. The wall's
+// restitution coefficient simulates energy loss on impact and its
+// friction slows sliding along the wall.
+func (w *World) bounce(b *Ball, edge int, normal, wallVel Vector) {
+	// A contact that carries over from the previous step is the same
+	// collision continuing.
+	b.contact[edge] = true
+	newContact := !b.prevContact[edge]
+
+	// Compute the ball’s velocity relative to the moving wall.
+	velBefore := b.Vel
+	relVel := b.Vel.Sub(wallVel)
+	// Check if the ball is moving into the wall (dot product is negative).
+	dot := relVel.Dot(normal)
+	if dot >= 0 {
+		return
+	}
+	material := w.Material(edge)
+	restitution := material.Restitution
+	// An elastic bounce keeps all of its speed, however hard or grazing.
+	if w.Mode != CollisionsElastic {
+		if w.RestitutionFn != nil {
+			restitution *= w.RestitutionFn(-dot)
+		}
+		restitution = w.effectiveRestitution(restitution, relVel, normal)
+	}
+	// Reflect the relative velocity off the wall, then take away the part
+	// of the normal speed the restitution doesn't keep.
+	relVel = relVel.Reflect(normal)
+	relVel = relVel.Add(normal.Mul((1 - restitution) * dot))
+
+	// Coulomb friction acts on the sliding of the ball's surface against
+	// the wall at the contact, which includes the ball's spin. The
+	// tangential impulse is at most friction times the normal impulse,
+	// and never more than what it takes to stop the sliding (so it can't
+	// reverse direction). The ball is a solid disk, so an impulse J along
+	// the wall changes the sliding speed by 3J: J through the linear
+	// motion and 2J through the spin it imparts.
+	normalImpulse := -(1 + restitution) * dot
+	radius := w.CollisionRadius
+	arm := normal.Mul(-radius) // From the ball's center to the contact.
+	surfaceVel := relVel.Add(arm.Perp().Mul(b.AngularVel))
+	tangent := surfaceVel.Sub(normal.Mul(surfaceVel.Dot(normal)))
+	if slide := tangent.Len(); slide > 0 && radius > 0 {
+		frictionImpulse := math.Min(material.Friction*normalImpulse, slide/3)
+		impulse := tangent.Mul(-frictionImpulse / slide)
+		relVel = relVel.Add(impulse)
+		// The impulse acts at the contact, so it also torques the ball:
+		// Δω = (arm × J) / I, with I = radius²/2 per unit mass.
+		b.AngularVel += arm.Cross(impulse) / (0.5 * radius * radius)
+	}
+	// The new ball velocity is the reflected relative velocity plus the wall’s velocity.
+	b.Vel = relVel.Add(wallVel)
+
+	// Harder hits heat the ball more; heat saturates at 1.
+	b.Heat = math.Min(1, b.Heat-dot*w.HeatPerSpeed)
+	w.WallHeat[edge] = math.Min(1, w.WallHeat[edge]-dot*w.HeatPerSpeed)
+	w.Stats.recordBounce(edge, -dot)
+	if newContact && w.OnCollision != nil {
+		w.OnCollision(Collision{
+			Ball:        b,
+			Edge:        edge,
+			Point:       b.Pos.Sub(normal.Mul(w.CollisionRadius)),
+			Normal:      normal,
+			ImpactSpeed: -dot,
+			VelBefore:   velBefore,
+		})
+	}
+}
+
+// keepInside handles a ball whose center ended up outside the container.
+// If the nearest wall is open, the ball has escaped through it. Otherwise
+// this is the safety net for a ball that got out some other way (through
+// tunneling, a resize, a bad saved state...), where no wall can ever
+// bounce it back: it moves the ball just inside the nearest wall, dropping
+// any velocity pointing out through it, or to the center if that spot
+// isn't inside either.
+func (w *World) keepInside(b *Ball, edges [][2]Vector) {
+	center := w.Container.Center()
+	var normal, pos Vector
+	if circle, ok := w.Container.(*Circle); ok {
+		offset := b.Pos.Sub(center)
+		if offset.Len() <= circle.Radius() {
+			return
+		}
+		if w.OpenWalls[0] {
+			w.exit(b, 0)
+			return
+		}
+		normal = offset.Normalize().Mul(-1)
+		pos = center.Sub(normal.Mul(circle.Radius() - w.CollisionRadius))
+	} else {
+		if len(edges) == 0 || pointInPolygon(b.Pos, edges) {
+			return
+		}
+		normals := inwardNormals(edges)
+		best, nearest := math.Inf(1), -1
+		for i, e := range edges {
+			closest := closestPointOnSegment(e[0], e[1], b.Pos)
+			if d := closest.Distance(b.Pos); d < best {
+				best, nearest = d, i
+				normal = normals[i]
+				pos = closest.Add(normal.Mul(w.CollisionRadius))
+			}
+		}
+		if w.OpenWalls[nearest] {
+			w.exit(b, nearest)
+			return
+		}
+		if !pointInPolygon(pos, edges) {
+			pos = center
+		}
+	}
+	from := b.Pos
+	b.Pos = pos
+	if out := b.Vel.Dot(normal); out < 0 {
+		b.Vel = b.Vel.Sub(normal.Mul(out))
+	}
+	if w.OnEscape != nil {
+		w.OnEscape(b, from)
+	}
+}
+
+// exit marks ball b as having escaped through open wall number wall.
+func (w *World) exit(b *Ball, wall int) {
+	b.Escaped = true
+	if w.OnExit != nil {
+		w.OnExit(b, wall)
+	}
+}
+
+// effectiveRestitution returns the restitution for a hit with the given
+// relative velocity against a wall with the given normal and head-on
+// restitution. It blends from restitution (head-on) to
+// restitution*GrazingFactor (grazing) linearly in the angle of incidence.
+func (w *World) effectiveRestitution(restitution float64, relVel, normal Vector) float64 {
+	speed := relVel.Len()
+	if speed == 0 {
+		return restitution
+	}
+	cos := math.Min(1, -relVel.Dot(normal)/speed)
+	t := math.Acos(cos) / (math.Pi / 2)
+	factor := 1 + (w.GrazingFactor-1)*t
+	return math.Max(0, restitution*factor)
+}
@@ -0,0 +1,441 @@
+package physics
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// newTestWorld returns a world with a still hexagon of radius 200 and no
+// gravity, drag or friction, holding a 10 px ball at each of positions
+// moving with the matching velocity.
+func newTestWorld(t testing.TB, restitution float64, pos, vel []Vector) *World {
+	t.Helper()
+	w := &World{
+		BallRadius:      10,
+		CollisionRadius: 10,
+		Restitution:     restitution,
+		GrazingFactor:   1,
+		GravityDir:      Vector{X: 0, Y: 1},
+	}
+	w.SetContainer(NewRegularPolygon(Vector{X: 400, Y: 300}, 200, 6))
+	for i := range pos {
+		b, err := NewBall(pos[i], vel[i], 1, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Balls = append(w.Balls, b)
+	}
+	return w
+}
+
+// TestEnergyDecay checks that with restitution below 1 and still walls a
+// bouncing ball only ever loses energy, and eventually almost all of it.
+func TestEnergyDecay(t *testing.T) {
+	w := newTestWorld(t, 0.8, []Vector{{X: 400, Y: 300}}, []Vector{{X: 530, Y: 170}})
+	start := w.TotalEnergy()
+	prev := start
+	for i := range 5000 {
+		w.Step(1.0 / 60)
+		e := w.TotalEnergy()
+		if e > prev*(1+1e-9) {
+			t.Fatalf("step %d: energy rose from %v to %v", i, prev, e)
+		}
+		prev = e
+	}
+	if prev > 0.01*start {
+		t.Errorf("energy only fell from %v to %v", start, prev)
+	}
+}
+
+// TestEnergyDecayBetweenBalls checks the same with ball-to-ball
+// collisions.
+func TestEnergyDecayBetweenBalls(t *testing.T) {
+	pos := []Vector{{X: 300, Y: 300}, {X: 500, Y: 300}, {X: 400, Y: 200}}
+	vel := []Vector{{X: 300, Y: 20}, {X: -300, Y: 0}, {X: 0, Y: 250}}
+	w := newTestWorld(t, 0.8, pos, vel)
+	w.BallCollisions = true
+	start := w.TotalEnergy()
+	prev := start
+	for i := range 5000 {
+		w.Step(1.0 / 60)
+		e := w.TotalEnergy()
+		if e > prev*(1+1e-9) {
+			t.Fatalf("step %d: energy rose from %v to %v", i, prev, e)
+		}
+		prev = e
+	}
+	if math.IsNaN(prev) || prev > 0.01*start {
+		t.Errorf("energy only fell from %v to %v", start, prev)
+	}
+}
+
+// newCrowdedWorld returns a world like newTestWorld's under gravity, with
+// n colliding balls laid out on a grid about the center and moving in
+// varied directions.
+func newCrowdedWorld(b testing.TB, n int) *World {
+	var pos, vel []Vector
+	for y := -170.0; y <= 170 && len(pos) < n; y += 21 {
+		for x := -170.0; x <= 170 && len(pos) < n; x += 21 {
+			if math.Hypot(x, y) > 170 {
+				continue
+			}
+			pos = append(pos, Vector{X: 400 + x, Y: 300 + y})
+			vel = append(vel, Vector{X: 150, Y: 0}.Rotate(float64(len(vel))))
+		}
+	}
+	if len(pos) < n {
+		b.Fatalf("only room for %d balls", len(pos))
+	}
+	w := newTestWorld(b, 0.9, pos, vel)
+	w.BallCollisions = true
+	w.Gravity, w.GravityOn = 500, true
+	w.AngularSpeed = 1
+	return w
+}
+
+// BenchmarkStep measures a frame's physics with 200 colliding balls.
+func BenchmarkStep(b *testing.B) {
+	w := newCrowdedWorld(b, 200)
+	b.ResetTimer()
+	for range b.N {
+		w.Step(1.0 / 60)
+	}
+}
+
+// minWallDistance returns the smallest distance from p to any of edges.
+func minWallDistance(p Vector, edges [][2]Vector) float64 {
+	best := math.Inf(1)
+	for _, e := range edges {
+		best = math.Min(best, closestPointOnSegment(e[0], e[1], p).Distance(p))
+	}
+	return best
+}
+
+// checkPenetration steps w for the given number of frames of dt seconds,
+// each split into substeps, and fails if a ball ever ends a frame outside
+// the container or sunk into a wall by more than tolerance.
+func checkPenetration(t *testing.T, w *World, frames, substeps int, dt, tolerance float64) {
+	t.Helper()
+	for frame := range frames {
+		for range substeps {
+			w.Step(dt / float64(substeps))
+		}
+		edges := w.Container.Edges(w.Rotation)
+		var verts []Vector
+		for _, e := range edges {
+			verts = append(verts, e[0])
+		}
+		for i, b := range w.Balls {
+			if !PointInPolygon(b.Pos, verts) {
+				t.Fatalf("frame %d: ball %d escaped to %v", frame, i, b.Pos)
+			}
+			if d := minWallDistance(b.Pos, edges); d < w.CollisionRadius-tolerance {
+				t.Fatalf("frame %d: ball %d sank %v px into a wall", frame, i, w.CollisionRadius-d)
+			}
+		}
+	}
+}
+
+// TestNoPenetration runs crowded, spinning containers and checks that no
+// ball ever overlaps a wall.
+func TestNoPenetration(t *testing.T) {
+	tests := []struct {
+		angularSpeed, restitution float64
+		substeps                  int
+	}{
+		{0, 0.5, 1},
+		{0, 1, 1},
+		{0.5, 0.9, 1},
+		{3, 0.5, 1},
+		{3, 1, 1},
+		// Faster walls move more than a ball diameter per frame, which
+		// takes sub-stepping.
+		{8, 0.9, 4},
+	}
+	for _, tt := range tests {
+		name := fmt.Sprintf("spin %v restitution %v", tt.angularSpeed, tt.restitution)
+		t.Run(name, func(t *testing.T) {
+			w := newCrowdedWorld(t, 10)
+			w.AngularSpeed, w.Restitution = tt.angularSpeed, tt.restitution
+			checkPenetration(t, w, 1800, tt.substeps, 1.0/60, 1e-6)
+		})
+	}
+}
+
+// TestCornerPenetration drives a ball hard into a corner of the still
+// container and keeps it there: the two walls meeting there must not let
+// it sink in or slip out between them.
+func TestCornerPenetration(t *testing.T) {
+	w := newTestWorld(t, 0.5, []Vector{{X: 400, Y: 300}}, []Vector{{}})
+	corner := w.Container.Edges(0)[0][0]
+	w.GravityDir = corner.Sub(w.Container.Center()).Normalize()
+	w.Gravity, w.GravityOn = 20000, true
+	checkPenetration(t, w, 600, 1, 1.0/60, 1e-6)
+
+	if d := w.Balls[0].Pos.Distance(corner); d > 2*w.CollisionRadius {
+		t.Errorf("ball ended %v px from the corner it was driven into", d)
+	}
+}
+
+// BenchmarkSubsteps measures a frame's physics split into K sub-steps, as
+// the game's -substeps does, with 200 colliding balls.
+func BenchmarkSubsteps(b *testing.B) {
+	for _, k := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("K=%d", k), func(b *testing.B) {
+			w := newCrowdedWorld(b, 200)
+			b.ResetTimer()
+			for range b.N {
+				for range k {
+					w.Step(1.0 / 60 / float64(k))
+				}
+			}
+		})
+	}
+}
+
+// TestElasticConservesEnergy checks that in CollisionsElastic mode a
+// still container without gravity or drag keeps the balls' energy, even
+// with lossy walls configured.
+func TestElasticConservesEnergy(t *testing.T) {
+	pos := []Vector{{X: 300, Y: 300}, {X: 500, Y: 300}, {X: 400, Y: 200}}
+	vel := []Vector{{X: 300, Y: 20}, {X: -300, Y: 0}, {X: 0, Y: 250}}
+	w := newTestWorld(t, 0.5, pos, vel)
+	w.Friction, w.GrazingFactor = 0.8, 0.5
+	w.RestitutionFn = func(speed float64) float64 { return 0.5 }
+	w.BallCollisions = true
+	w.Mode = CollisionsElastic
+	start := w.TotalEnergy()
+	bounces := 0
+	w.OnCollision = func(Collision) { bounces++ }
+	for range 3600 {
+		w.Step(1.0 / 60)
+	}
+	if bounces == 0 {
+		t.Fatal("no bounces to test")
+	}
+	if end := w.TotalEnergy(); math.Abs(end-start) > 1e-9*start {
+		t.Errorf("energy went from %v to %v over %d bounces", start, end, bounces)
+	}
+}
+
+// TestStats runs a ball straight down and back up the hexagon for two
+// seconds: with walls 163.2 px away at 300 px/s, it hits the bottom wall
+// and then the top one, each at full speed.
+func TestStats(t *testing.T) {
+	w := newTestWorld(t, 1, []Vector{{X: 400, Y: 300}}, []Vector{{X: 0, Y: 300}})
+	var hits []Collision
+	w.OnCollision = func(c Collision) { hits = append(hits, c) }
+	const dt = 1.0 / 120
+	for range 240 {
+		w.Step(dt)
+	}
+
+	s := w.Stats
+	if s.Bounces != 2 || len(hits) != 2 {
+		t.Fatalf("%d bounces, %d collisions; want 2 of each", s.Bounces, len(hits))
+	}
+	// The bottom wall runs from vertex 1 to 2, the top one from 4 to 5.
+	for i, n := range s.EdgeBounces {
+		want := 0
+		if i == 1 || i == 4 {
+			want = 1
+		}
+		if n != want {
+			t.Errorf("edge %d: %d bounces, want %d", i, n, want)
+		}
+	}
+	if hits[0].Edge != 1 || hits[1].Edge != 4 {
+		t.Errorf("hit edges %d then %d, want 1 then 4", hits[0].Edge, hits[1].Edge)
+	}
+	if math.Abs(s.ImpactMax-300) > 1e-6 || math.Abs(s.ImpactSum-600) > 1e-6 {
+		t.Errorf("impact max %g, sum %g; want 300 and 600", s.ImpactMax, s.ImpactSum)
+	}
+	// The straight-line distance of each step cuts the corner of a bounce
+	// by at most one step's travel.
+	if want := 300.0 * 2; math.Abs(s.Distance-want) > 2*300*dt {
+		t.Errorf("distance %g, want about %g", s.Distance, want)
+	}
+	if e := w.TotalEnergy(); math.Abs(e-0.5*300*300) > 1e-6 {
+		t.Errorf("final energy %g, want %g", e, 0.5*300*300)
+	}
+}
+
+// TestCollisionRadius checks that walls and other balls are hit at the
+// collision radius, whether it is smaller or larger than the drawn one.
+func TestCollisionRadius(t *testing.T) {
+	const dt = 1.0 / 120
+	for _, radius := range []float64{5, 20} {
+		w := newTestWorld(t, 1, []Vector{{X: 400, Y: 300}}, []Vector{{X: 0, Y: 300}})
+		w.CollisionRadius = radius
+		b := w.Balls[0]
+		closest := math.Inf(1)
+		for range 80 {
+			w.Step(dt)
+			closest = math.Min(closest, minWallDistance(b.Pos, w.Container.Edges(w.Rotation)))
+		}
+		if b.Vel.Y >= 0 {
+			t.Fatalf("radius %g: the ball never bounced", radius)
+		}
+		// The ball turns back on touching the wall, ending that step at most
+		// a step's travel away from it.
+		if closest < radius-epsilon || closest > radius+300*dt {
+			t.Errorf("radius %g: the ball came within %g px of the wall", radius, closest)
+		}
+
+		// Balls 30 px apart, clear of each other at the drawn radius,
+		// overlap only at the larger collision radius.
+		a, _ := NewBall(Vector{X: 0, Y: 0}, Vector{X: 10, Y: 0}, 1, 0)
+		c, _ := NewBall(Vector{X: 30, Y: 0}, Vector{}, 1, 0)
+		w.collidePair(a, c)
+		if hit := c.Vel.X != 0; hit != (2*radius > 30) {
+			t.Errorf("radius %g: balls 30 px apart collided = %v", radius, hit)
+		}
+	}
+}
+
+// TestMaxSpeed checks that the speed cap scales a fast ball down to the
+// limit without turning it, and leaves slower balls alone.
+func TestMaxSpeed(t *testing.T) {
+	fast, slow := Vector{X: 600, Y: -800}, Vector{X: -30, Y: 40}
+	w := newTestWorld(t, 1, []Vector{{X: 400, Y: 300}, {X: 300, Y: 300}}, []Vector{fast, slow})
+	w.MaxSpeed = 250
+	w.Step(1.0 / 120)
+
+	b := w.Balls[0]
+	if math.Abs(b.Vel.Len()-250) > epsilon {
+		t.Errorf("fast ball at %v px/s, want it capped to 250", b.Vel.Len())
+	}
+	if !near(b.Vel.Normalize(), fast.Normalize()) {
+		t.Errorf("the cap turned the ball from %v to %v", fast.Normalize(), b.Vel.Normalize())
+	}
+	if v := w.Balls[1].Vel; !near(v, slow) {
+		t.Errorf("slow ball changed from %v to %v", slow, v)
+	}
+}
+
+// TestConcaveQuad bounces a ball in a dart-shaped container off one of
+// the walls leading into its notch. That wall's inner side faces away
+// from the dart's centroid, so only the winding gives the right normal.
+func TestConcaveQuad(t *testing.T) {
+	dart, err := NewPolygon([]Vector{{X: 300, Y: 200}, {X: 700, Y: 400}, {X: 300, Y: 600}, {X: 500, Y: 400}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newTestWorld(t, 1, []Vector{{X: 450, Y: 310}}, []Vector{{X: -300, Y: 300}})
+	w.SetContainer(dart)
+	var hits []Collision
+	w.OnCollision = func(c Collision) { hits = append(hits, c) }
+	for range 10 {
+		w.Step(1.0 / 120)
+	}
+	// The wall from (500, 400) to (300, 200) faces up and to the right.
+	if len(hits) != 1 || hits[0].Edge != 3 {
+		t.Fatalf("collisions %+v, want one with wall 3", hits)
+	}
+	if want := (Vector{X: 1, Y: -1}).Normalize(); !near(hits[0].Normal, want) {
+		t.Errorf("wall normal %v, want %v", hits[0].Normal, want)
+	}
+	if v := w.Balls[0].Vel; !near(v, Vector{X: 300, Y: -300}) {
+		t.Errorf("ball left the wall at %v, want straight back at (300, -300)", v)
+	}
+
+	// Falling balls must stay out of the walls, the notch's included.
+	w = newTestWorld(t, 0.9, []Vector{{X: 580, Y: 400}, {X: 420, Y: 300}, {X: 420, Y: 500}}, make([]Vector, 3))
+	w.SetContainer(dart)
+	w.Gravity, w.GravityOn = 600, true
+	checkPenetration(t, w, 1200, 1, 1.0/60, 1e-6)
+}
+
+// TestVertexApproach sends a ball at a vertex of the still hexagon, a
+// little off its bisector: once coasting in, and once pressed into it by
+// gravity, so that it touches both walls there. Each step must resolve a
+// single contact: one collision and one reflection about the normal of
+// the wall the ball sank deepest into, moving it no farther than it sank
+// (in the corner's two walls, no farther than the point clear of both).
+func TestVertexApproach(t *testing.T) {
+	const dt = 1.0 / 60
+	vertex := Vector{X: 600, Y: 300}
+	tests := []struct {
+		name        string
+		restitution float64
+		vel         Vector
+		gravity     float64 // Toward just below the vertex.
+	}{
+		{"coasting", 1, Vector{X: 60, Y: 0}, 0},
+		{"pressed", 0.5, Vector{}, 3000},
+	}
+	for _, tt := range tests {
+		w := newTestWorld(t, tt.restitution, []Vector{{X: 560, Y: 303}}, []Vector{tt.vel})
+		b := w.Balls[0]
+		w.GravityDir = vertex.Add(Vector{X: 0, Y: 2}).Sub(b.Pos).Normalize()
+		w.Gravity, w.GravityOn = tt.gravity, tt.gravity > 0
+		edges := w.Container.Edges(0)
+		normals := inwardNormals(edges)
+		if !near(edges[0][0], vertex) {
+			t.Fatalf("wall 0 starts at %v, want the vertex %v", edges[0][0], vertex)
+		}
+		var hits []Collision
+		w.OnCollision = func(c Collision) { hits = append(hits, c) }
+
+		bounces, both := 0, 0
+		for step := range 240 {
+			hits = hits[:0]
+			// Without a wall in the way the ball would end the step here,
+			// moving at velBefore.
+			velBefore := b.Vel.Add(w.GravityDir.Mul(w.CurrentGravity() * dt))
+			free := b.Pos.Add(velBefore.Mul(dt))
+			deepest, depth := -1, 0.0
+			var sunk []int
+			for i, e := range edges {
+				d := w.CollisionRadius - closestPointOnSegment(e[0], e[1], free).Distance(free)
+				if d > 0 {
+					sunk = append(sunk, i)
+				}
+				if d > depth {
+					deepest, depth = i, d
+				}
+			}
+			// Sunk into both walls of the corner, the ball has to go as far
+			// as the point clear of both.
+			limit := depth
+			if len(sunk) == 2 {
+				i, j := sunk[0], sunk[1]
+				clear, _ := clearOfBoth(edges[i][0], normals[i], edges[j][0], normals[j], w.CollisionRadius)
+				limit = clear.Distance(free)
+				both++
+			}
+			w.Step(dt)
+
+			if deepest < 0 {
+				if len(hits) != 0 || !near(b.Pos, free) {
+					t.Fatalf("%s, step %d: the ball touched no wall but bounced to %v", tt.name, step, b.Pos)
+				}
+				continue
+			}
+			if len(hits) > 1 {
+				t.Fatalf("%s, step %d: %d collisions in one step", tt.name, step, len(hits))
+			}
+			if jump := b.Pos.Distance(free); jump > limit+1e-6 {
+				t.Fatalf("%s, step %d: the ball sank %v px but was moved %v px", tt.name, step, limit, jump)
+			}
+			n := normals[deepest]
+			if dot := velBefore.Dot(n); dot < 0 {
+				bounces++
+				want := velBefore.Sub(n.Mul((1 + tt.restitution) * dot))
+				if !near(b.Vel, want) {
+					t.Fatalf("%s, step %d: velocity %v became %v, want %v off wall %d only", tt.name, step, velBefore, b.Vel, want, deepest)
+				}
+				if len(hits) == 1 && hits[0].Edge != deepest {
+					t.Errorf("%s, step %d: collision with wall %d, want the deepest, %d", tt.name, step, hits[0].Edge, deepest)
+				}
+			}
+		}
+		if bounces == 0 {
+			t.Errorf("%s: the ball never reached the vertex", tt.name)
+		}
+		if tt.gravity > 0 && both == 0 {
+			t.Errorf("%s: the ball never touched both walls at once", tt.name)
+		}
+	}
+}
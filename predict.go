@@ -0,0 +1,41 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// ----------------------------------------------------
+// Trajectory prediction.
+// ----------------------------------------------------
+
+// predictDotSpacing is how many predicted steps lie between drawn dots.
+const predictDotSpacing = 3
+
+// predictionSteps returns how many physics steps cover the configured
+// prediction horizon.
+func (g *Game) predictionSteps() int {
+	return int(g.cfg.PredictTime / physicsDT)
+}
+
+// drawPredictions draws each ball's predicted path (see
+// physics.World.Predict) as a faint dotted line, up to its first
+// collision.
+func (g *Game) drawPredictions(screen *ebiten.Image, view ebiten.GeoM) {
+	steps := g.predictionSteps()
+	if steps <= 0 {
+		return
+	}
+	for _, b := range g.world.Balls {
+		dotColor := color.NRGBA{b.Color.R, b.Color.G, b.Color.B, 110}
+		for i, p := range g.world.Predict(b, steps, physicsDT*g.timeScale) {
+			if i%predictDotSpacing != predictDotSpacing-1 {
+				continue
+			}
+			x, y := view.Apply(p.X, p.Y)
+			vector.DrawFilledCircle(screen, float32(x), float32(y), 1.5, dotColor, true)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// ----------------------------------------------------
+// Preset scenarios.
+// ----------------------------------------------------
+
+// presetBannerFrames is how many Updates the name of a newly chosen preset
+// stays on screen (two seconds at 60 TPS).
+const presetBannerFrames = 120
+
+// preset is a named demo scenario: changes applied on top of the
+// configuration the game was started with.
+type preset struct {
+	name  string
+	apply func(c *Config)
+}
+
+// presets lists the scenarios N cycles through.
+var presets = []preset{
+	{"single ball, slow spin", func(c *Config) {
+		c.Balls, c.AngularSpeed = 1, 0.3
+	}},
+	{"many balls, fast spin", func(c *Config) {
+		c.Balls, c.AngularSpeed = 30, 3
+	}},
+	{"zero gravity", func(c *Config) {
+		c.Balls, c.Gravity = 8, 0
+	}},
+	{"billiards", func(c *Config) {
+		c.Balls, c.NoGravity = 6, true
+		c.Drag, c.Friction, c.Restitution = 0, 0, 1
+	}},
+	{"sideways gravity", func(c *Config) {
+		c.Balls, c.GravityAngle = 4, 0
+	}},
+	{"octagon", func(c *Config) {
+		c.Container, c.Sides, c.Points = "polygon", 8, nil
+	}},
+	{"nested rings", func(c *Config) {
+		c.Balls, c.Rings = 6, 2
+	}},
+	{"orbit", func(c *Config) {
+		c.Balls, c.Gravity, c.Drag, c.GravityWell = 1, 0, 0, true
+	}},
+	{"escape hatch", func(c *Config) {
+		c.Container, c.Points = "polygon", nil
+		c.Balls, c.OpenEdge, c.GatePeriod = 10, 0, 3
+	}},
+}
+
+// nextPreset switches to the preset after the current one, wrapping
+// around, and rebuilds the game from it. Session state (input recording,
+// GIF recording, collision log, the script, hooks and view settings)
+// carries over.
+func (g *Game) nextPreset() {
+	i := (g.preset + 1) % len(presets)
+	p := presets[i]
+	cfg := g.baseCfg
+	p.apply(&cfg)
+	if err := cfg.Validate(); err != nil {
+		log.Printf("preset %q: %v", p.name, err)
+		return
+	}
+	ng, err := NewGame(cfg, g.theme)
+	if err != nil {
+		log.Printf("preset %q: %v", p.name, err)
+		return
+	}
+	ng.baseCfg = g.baseCfg
+	ng.preset = i
+	ng.banner = fmt.Sprintf("preset: %s", p.name)
+	ng.bannerUntil = g.frame + presetBannerFrames
+
+	ng.OnCollision, ng.OnExit = g.OnCollision, g.OnExit
+	ng.script = g.script
+	ng.collisionLog = g.collisionLog
+	ng.input, ng.frame = g.input, g.frame
+	ng.inputLog, ng.replay, ng.lockstep = g.inputLog, g.replay, g.lockstep
+	ng.recorder = g.recorder
+	ng.showHUD, ng.showGrid, ng.rotatingFrame = g.showHUD, g.showGrid, g.rotatingFrame
+	ng.showSettings, ng.settingIndex = g.showSettings, g.settingIndex
+	ng.speedTrails, ng.showSpeedometer = g.speedTrails, g.showSpeedometer
+	ng.windowW, ng.windowH = g.windowW, g.windowH
+	ng.origin, ng.windowKeys = g.origin, g.windowKeys
+	// The window keeps its size; Layout re-lays out the new scene for it.
+	*g = *ng
+	g.bindWorld()
+}
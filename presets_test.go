@@ -0,0 +1,17 @@
+package main
+
+import "testing"
+
+// TestNextPresetKeepsHooks switches preset and checks that the world's
+// collisions still reach the game itself, not the one it was copied from.
+func TestNextPresetKeepsHooks(t *testing.T) {
+	g := newTestGame(t)
+	g.nextPreset()
+	if g.preset != 0 {
+		t.Fatalf("preset %d after switching, want 0", g.preset)
+	}
+	g.Advance(3)
+	if g.collisions == 0 || g.world.Stats.Bounces == 0 {
+		t.Errorf("%d collisions counted for %d bounces after switching preset", g.collisions, g.world.Stats.Bounces)
+	}
+}
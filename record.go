@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ----------------------------------------------------
+// Screenshots and GIF recording.
+// ----------------------------------------------------
+
+// saveScreenshot writes the screen contents to a timestamped PNG file in
+// the working directory and returns its name.
+func saveScreenshot(screen *ebiten.Image) (string, error) {
+	img := image.NewRGBA(screen.Bounds())
+	screen.ReadPixels(img.Pix)
+	name := "hexagon-" + time.Now().Format("20060102-150405") + ".png"
+	f, err := os.Create(name)
+	if err != nil {
+		return "", err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return "", err
+	}
+	return name, f.Close()
+}
+
+// gifFrameInterval is the spacing between captured frames; GIF delays are
+// in hundredths of a second, so frames are captured at about 30 per second.
+const (
+	gifFrameInterval = time.Second / 30
+	gifFrameDelay    = 3
+)
+
+// gifRecorder collects rendered frames and writes them out as an
+// animated GIF.
+type gifRecorder struct {
+	maxFrames   int
+	frames      []*image.Paletted
+	delays      []int
+	lastCapture time.Time
+}
+
+// newGIFRecorder starts a recording that keeps at most maxFrames frames.
+func newGIFRecorder(maxFrames int) *gifRecorder {
+	return &gifRecorder{maxFrames: maxFrames}
+}
+
+// capture grabs the current screen contents if enough time has passed
+// since the previous frame. It reports whether the recording is full.
+func (r *gifRecorder) capture(screen *ebiten.Image) bool {
+	now := time.Now()
+	if len(r.frames) > 0 && now.Sub(r.lastCapture) < gifFrameInterval {
+		return false
+	}
+	r.lastCapture = now
+
+	bounds := screen.Bounds()
+	rgba := image.NewRGBA(bounds)
+	screen.ReadPixels(rgba.Pix)
+	frame := image.NewPaletted(bounds, palette.Plan9)
+	draw.Draw(frame, bounds, rgba, bounds.Min, draw.Src)
+	r.frames = append(r.frames, frame)
+	r.delays = append(r.delays, gifFrameDelay)
+	return len(r.frames) >= r.maxFrames
+}
+
+// save encodes the captured frames into a timestamped GIF file in the
+// working directory and returns its name.
+func (r *gifRecorder) save() (string, error) {
+	if len(r.frames) == 0 {
+		return "", fmt.Errorf("no frames recorded")
+	}
+	name := "hexagon-" + time.Now().Format("20060102-150405") + ".gif"
+	f, err := os.Create(name)
+	if err != nil {
+		return "", err
+	}
+	if err := gif.EncodeAll(f, &gif.GIF{Image: r.frames, Delay: r.delays}); err != nil {
+		f.Close()
+		return "", err
+	}
+	return name, f.Close()
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ----------------------------------------------------
+// Recording and replaying input.
+// ----------------------------------------------------
+
+// A recording is a JSON file holding the seed and every change in input,
+// tagged with the Update (frame) it happened in, for example:
+//
+//	{
+//	  "seed": 1,
+//	  "events": [
+//	    {"frame": 120, "key": "G", "down": true},
+//	    {"frame": 124, "key": "G"},
+//	    {"frame": 300, "cursor": {"X": 412, "Y": 250}},
+//	    {"frame": 301, "button": "left", "down": true}
+//	  ]
+//	}
+//
+// The physics is deterministic, so replaying the events from the same
+// seed and flags reproduces the run, as long as the window keeps its size.
+// While recording or replaying, every Update advances the physics by
+// exactly one fixed step, so the run doesn't depend on the frame timing.
+
+// inputEvent is one change in input. Exactly one of Key, Button, Cursor
+// and Wheel is set.
+type inputEvent struct {
+	Frame  int         `json:"frame"`
+	Key    *ebiten.Key `json:"key,omitempty"`
+	Button string      `json:"button,omitempty"` // "left", "right" or "middle".
+	Down   bool        `json:"down,omitempty"`   // For keys and buttons: pressed, else released.
+	Cursor *Vector     `json:"cursor,omitempty"` // New cursor position.
+	Wheel  float64     `json:"wheel,omitempty"`  // Vertical wheel movement.
+}
+
+// buttonNames names the tracked mouse buttons in recordings.
+var buttonNames = map[ebiten.MouseButton]string{
+	ebiten.MouseButtonLeft:   "left",
+	ebiten.MouseButtonRight:  "right",
+	ebiten.MouseButtonMiddle: "middle",
+}
+
+// inputRecording is the contents of a recording file.
+type inputRecording struct {
+	Seed   int64        `json:"seed"`
+	Events []inputEvent `json:"events"`
+}
+
+// record appends the changes from input prev to in, seen in frame.
+func (r *inputRecording) record(frame int, prev, in inputState) {
+	for k := range in.keys {
+		if !prev.keys[k] {
+			r.Events = append(r.Events, inputEvent{Frame: frame, Key: &k, Down: true})
+		}
+	}
+	for k := range prev.keys {
+		if !in.keys[k] {
+			r.Events = append(r.Events, inputEvent{Frame: frame, Key: &k})
+		}
+	}
+	for _, b := range trackedButtons {
+		if in.buttons[b] != prev.buttons[b] {
+			r.Events = append(r.Events, inputEvent{Frame: frame, Button: buttonNames[b], Down: in.buttons[b]})
+		}
+	}
+	if in.cursor != prev.cursor {
+		cursor := in.cursor
+		r.Events = append(r.Events, inputEvent{Frame: frame, Cursor: &cursor})
+	}
+	if in.wheel != 0 {
+		r.Events = append(r.Events, inputEvent{Frame: frame, Wheel: in.wheel})
+	}
+}
+
+// save writes the recording to path.
+func (r *inputRecording) save(path string) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// loadRecording reads a recording file, checking that its events are in
+// frame order and name known buttons.
+func loadRecording(path string) (*inputRecording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r inputRecording
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parse recording %s: %w", path, err)
+	}
+	for i, e := range r.Events {
+		if i > 0 && e.Frame < r.Events[i-1].Frame {
+			return nil, fmt.Errorf("recording %s: events out of frame order", path)
+		}
+		if e.Button != "" && buttonByName(e.Button) < 0 {
+			return nil, fmt.Errorf("recording %s: unknown button %q", path, e.Button)
+		}
+	}
+	return &r, nil
+}
+
+// buttonByName looks up a button named in a recording, or returns -1.
+func buttonByName(name string) ebiten.MouseButton {
+	for b, n := range buttonNames {
+		if n == name {
+			return b
+		}
+	}
+	return -1
+}
+
+// replayer plays a recording back, one frame per Update.
+type replayer struct {
+	events []inputEvent
+	frame  int
+}
+
+// next returns the input for the next frame: prev with that frame's
+// events applied.
+func (p *replayer) next(prev inputState) inputState {
+	in := inputState{
+		keys:    maps.Clone(prev.keys),
+		buttons: maps.Clone(prev.buttons),
+		cursor:  prev.cursor,
+	}
+	if in.keys == nil {
+		in.keys = map[ebiten.Key]bool{}
+	}
+	if in.buttons == nil {
+		in.buttons = map[ebiten.MouseButton]bool{}
+	}
+	for len(p.events) > 0 && p.events[0].Frame <= p.frame {
+		e := p.events[0]
+		p.events = p.events[1:]
+		switch {
+		case e.Key != nil:
+			setHeld(in.keys, *e.Key, e.Down)
+		case e.Button != "":
+			setHeld(in.buttons, buttonByName(e.Button), e.Down)
+		case e.Cursor != nil:
+			in.cursor = *e.Cursor
+		default:
+			in.wheel = e.Wheel
+		}
+	}
+	p.frame++
+	return in
+}
+
+// done reports whether every event has been played.
+func (p *replayer) done() bool {
+	return len(p.events) == 0
+}
+
+// setHeld marks k as held down in set, or removes it.
+func setHeld[K comparable](set map[K]bool, k K, down bool) {
+	if down {
+		set[k] = true
+	} else {
+		delete(set, k)
+	}
+}
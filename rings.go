@@ -0,0 +1,33 @@
+package main
+
+import (
+	"math"
+
+	"michelo851a1203/hex-motion/physics"
+)
+
+// ----------------------------------------------------
+// Nested rings.
+// ----------------------------------------------------
+
+// newRings creates the configured rings: copies of the container shape,
+// scaled down in even steps about its center.
+func (g *Game) newRings() []*physics.Ring {
+	center := g.world.Container.Center()
+	rings := make([]*physics.Ring, g.cfg.Rings)
+	for k := range rings {
+		scale := float64(g.cfg.Rings-k) / float64(g.cfg.Rings+1)
+		rings[k] = &physics.Ring{Shape: g.world.Container.Place(center, scale)}
+	}
+	return rings
+}
+
+// ringSpeed returns ring k's configured angular speed. Rings without one
+// alternate direction, each faster than the container by another step of
+// its speed.
+func (g *Game) ringSpeed(k int) float64 {
+	if k < len(g.cfg.RingSpeeds) {
+		return g.cfg.RingSpeeds[k]
+	}
+	return g.cfg.AngularSpeed * float64(k+2) * math.Pow(-1, float64(k+1))
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+)
+
+// ----------------------------------------------------
+// Scripted demo sequences.
+// ----------------------------------------------------
+
+// A script is a JSON list of timed events, for example:
+//
+//	[
+//	  {"at": 2, "action": "gravity", "value": 900},
+//	  {"at": 3, "action": "spawn", "value": 4},
+//	  {"at": 5, "action": "restitution", "value": 0.5},
+//	  {"at": 6, "action": "camera", "x": 400, "y": 150, "value": 2},
+//	  {"at": 8, "action": "angularSpeed", "value": -1.5}
+//	]
+//
+// "at" is the simulated time in seconds at which the action fires. A
+// "spawn" adds value more balls; a "camera" move centers the view on the
+// point (x, y) zoomed in value times.
+
+// scriptEvent is a single timed action of a demo script.
+type scriptEvent struct {
+	At     float64 `json:"at"`
+	Action string  `json:"action"`
+	Value  float64 `json:"value"`
+	X      float64 `json:"x"` // Point a camera move centers on.
+	Y      float64 `json:"y"`
+}
+
+// scriptActions maps every supported action name to what it does, going
+// through the same setters as the keyboard and mouse controls.
+var scriptActions = map[string]func(g *Game, e scriptEvent){
+	"gravity":      func(g *Game, e scriptEvent) { g.setGravity(e.Value) },
+	"restitution":  func(g *Game, e scriptEvent) { g.world.Restitution = e.Value },
+	"angularSpeed": func(g *Game, e scriptEvent) { g.setAngularSpeed(e.Value) },
+	"spawn":        func(g *Game, e scriptEvent) { g.addBalls(int(e.Value)) },
+	"camera":       func(g *Game, e scriptEvent) { g.moveCamera(Vector{X: e.X, Y: e.Y}, e.Value) },
+}
+
+// validate reports an event that can't be played.
+func (e scriptEvent) validate() error {
+	if _, ok := scriptActions[e.Action]; !ok {
+		return fmt.Errorf("unknown action %q", e.Action)
+	}
+	switch {
+	case e.Action == "restitution" && !validRestitution(e.Value):
+		return fmt.Errorf("restitution at %gs must be between 0 and 1", e.At)
+	case e.Action == "spawn" && (e.Value < 1 || e.Value != math.Trunc(e.Value)):
+		return fmt.Errorf("spawn at %gs needs a whole, positive number of balls", e.At)
+	case e.Action == "camera" && e.Value <= 0:
+		return fmt.Errorf("camera zoom at %gs must be positive", e.At)
+	}
+	return nil
+}
+
+// loadScript reads a script file, validates its actions, and returns the
+// events sorted by time.
+func loadScript(path string) ([]scriptEvent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	events, err := parseScript(data)
+	if err != nil {
+		return nil, fmt.Errorf("script %s: %w", path, err)
+	}
+	return events, nil
+}
+
+// parseScript decodes and validates a script, returning the events sorted
+// by time.
+func parseScript(data []byte) ([]scriptEvent, error) {
+	var events []scriptEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		if err := e.validate(); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At < events[j].At })
+	return events, nil
+}
+
+// runScript applies, in order, every pending event whose time has come.
+func (g *Game) runScript() {
+	for g.scriptNext < len(g.script) && g.script[g.scriptNext].At <= g.simTime {
+		e := g.script[g.scriptNext]
+		scriptActions[e.Action](g, e)
+		g.scriptNext++
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// newTestGame creates a game with the default configuration and theme.
+func newTestGame(t *testing.T) *Game {
+	t.Helper()
+	g, err := NewGame(DefaultConfig(), themes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestParseScript(t *testing.T) {
+	events, err := parseScript([]byte(`[
+		{"at": 2, "action": "restitution", "value": 0.5},
+		{"at": 1, "action": "gravity", "value": 900},
+		{"at": 3, "action": "camera", "x": 10, "y": 20, "value": 2}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []scriptEvent{
+		{At: 1, Action: "gravity", Value: 900},
+		{At: 2, Action: "restitution", Value: 0.5},
+		{At: 3, Action: "camera", Value: 2, X: 10, Y: 20},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, events[i], want[i])
+		}
+	}
+}
+
+func TestParseScriptRejects(t *testing.T) {
+	tests := []struct {
+		script, want string
+	}{
+		{`{"at": 1}`, "cannot unmarshal"},
+		{`[{"at": 1, "action": "teleport", "value": 1}]`, `unknown action "teleport"`},
+		{`[{"at": 1, "action": "restitution", "value": 1.5}]`, "between 0 and 1"},
+		{`[{"at": 1, "action": "restitution", "value": -0.1}]`, "between 0 and 1"},
+		{`[{"at": 1, "action": "spawn", "value": 0}]`, "whole, positive"},
+		{`[{"at": 1, "action": "spawn", "value": 2.5}]`, "whole, positive"},
+		{`[{"at": 1, "action": "camera", "value": 0}]`, "zoom"},
+	}
+	for _, tt := range tests {
+		_, err := parseScript([]byte(tt.script))
+		if err == nil {
+			t.Errorf("%s: parseScript accepted it", tt.script)
+		} else if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error %q doesn't mention %q", tt.script, err, tt.want)
+		}
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	g := newTestGame(t)
+	g.script = []scriptEvent{
+		{At: 0.5, Action: "gravity", Value: 900},
+		{At: 1, Action: "restitution", Value: 0.25},
+		{At: 1, Action: "spawn", Value: 3},
+	}
+	gravity, restitution, balls := g.world.Gravity, g.world.Restitution, len(g.world.Balls)
+
+	g.Advance(0.25)
+	if g.world.Gravity != gravity {
+		t.Errorf("gravity changed to %g before its event", g.world.Gravity)
+	}
+	g.Advance(0.5)
+	if g.world.Gravity != 900 {
+		t.Errorf("gravity = %g after its event, want 900", g.world.Gravity)
+	}
+	if g.world.Restitution != restitution || len(g.world.Balls) != balls {
+		t.Error("a later event fired early")
+	}
+	g.Advance(0.5)
+	if g.world.Restitution != 0.25 {
+		t.Errorf("restitution = %g, want 0.25", g.world.Restitution)
+	}
+	if got := len(g.world.Balls); got != balls+3 {
+		t.Errorf("%d balls after spawning 3 onto %d", got, balls)
+	}
+	if g.scriptNext != len(g.script) {
+		t.Errorf("%d of %d events played", g.scriptNext, len(g.script))
+	}
+}
+
+func TestMoveCamera(t *testing.T) {
+	g := newTestGame(t)
+	p := Vector{X: 300, Y: 200}
+	g.moveCamera(p, 2)
+	if g.cameraScale != 2 {
+		t.Errorf("scale = %g, want 2", g.cameraScale)
+	}
+	// The point must be drawn at the middle of the screen.
+	got := p.Mul(g.cameraScale).Add(g.cameraOffset)
+	want := Vector{X: float64(g.screenW) / 2, Y: float64(g.screenH) / 2}
+	if math.Abs(got.X-want.X) > 1e-9 || math.Abs(got.Y-want.Y) > 1e-9 {
+		t.Errorf("point drawn at %v, want the screen center %v", got, want)
+	}
+
+	g.moveCamera(p, 1000)
+	if g.cameraScale != maxCameraScale {
+		t.Errorf("scale = %g, want it clamped to %g", g.cameraScale, maxCameraScale)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ----------------------------------------------------
+// Settings overlay.
+// ----------------------------------------------------
+
+// setting is one parameter the overlay can edit: how to read it, how to
+// change it (clamping as needed) and how much one left/right press does.
+type setting struct {
+	name   string
+	format string
+	step   float64
+	get    func(g *Game) float64
+	set    func(g *Game, v float64)
+}
+
+// settings lists the parameters in the overlay, top to bottom.
+var settings = []setting{
+	{"gravity", "%.0f px/s^2", gravityStep,
+		func(g *Game) float64 { return g.world.Gravity },
+		(*Game).setGravity},
+	{"restitution", "%.2f", 0.05,
+		func(g *Game) float64 { return g.world.Restitution },
+		func(g *Game, v float64) { g.world.Restitution = math.Max(0, math.Min(1, v)) }},
+	{"angular speed", "%.2f rad/s", angularStep,
+		(*Game).baseAngularSpeed,
+		(*Game).setAngularSpeed},
+	{"friction", "%.2f", 0.05,
+		func(g *Game) float64 { return g.world.Friction },
+		func(g *Game, v float64) { g.world.Friction = math.Max(0, v) }},
+}
+
+// updateSettings handles the overlay's keys while it is open: up/down
+// select a setting and left/right adjust it by one step.
+func (g *Game) updateSettings() {
+	n := len(settings)
+	if g.input.keyJustPressed(ebiten.KeyUp) {
+		g.settingIndex = (g.settingIndex + n - 1) % n
+	}
+	if g.input.keyJustPressed(ebiten.KeyDown) {
+		g.settingIndex = (g.settingIndex + 1) % n
+	}
+	s := settings[g.settingIndex]
+	if g.input.keyJustPressed(ebiten.KeyRight) {
+		s.set(g, s.get(g)+s.step)
+	}
+	if g.input.keyJustPressed(ebiten.KeyLeft) {
+		s.set(g, s.get(g)-s.step)
+	}
+}
+
+// settingsLines returns the overlay text, one entry per line, marking the
+// selected setting.
+func (g *Game) settingsLines() []string {
+	lines := []string{"settings (Tab to close, up/down select, left/right adjust)"}
+	for i, s := range settings {
+		marker := " "
+		if i == g.settingIndex {
+			marker = ">"
+		}
+		lines = append(lines, fmt.Sprintf("%s %-14s "+s.format, marker, s.name+":", s.get(g)))
+	}
+	return lines
+}
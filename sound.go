@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"io"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+)
+
+// ----------------------------------------------------
+// Bounce sound.
+// ----------------------------------------------------
+
+//go:embed assets/bounce.wav
+var bounceWAV []byte
+
+const (
+	audioSampleRate = 44100
+
+	// Impacts at soundFullSpeed (px/s) or faster play at full volume;
+	// softer ones scale down linearly, and those below soundMinSpeed are
+	// silent.
+	soundFullSpeed = 800.0
+	soundMinSpeed  = 20.0
+
+	// soundMinInterval is the least real time between two sounds, so a
+	// ball jittering against a wall (or many balls at once) doesn't
+	// machine-gun the effect.
+	soundMinInterval = 40 * time.Millisecond
+)
+
+// bounceSound plays the bounce effect on collisions.
+type bounceSound struct {
+	ctx      *audio.Context
+	pcm      []byte // Decoded samples, replayed by a fresh player per hit.
+	lastPlay time.Time
+}
+
+// newBounceSound sets up the audio context and decodes the embedded sound.
+// Only one audio context may exist per process.
+func newBounceSound() (*bounceSound, error) {
+	stream, err := wav.DecodeWithSampleRate(audioSampleRate, bytes.NewReader(bounceWAV))
+	if err != nil {
+		return nil, err
+	}
+	pcm, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, err
+	}
+	return &bounceSound{ctx: audio.NewContext(audioSampleRate), pcm: pcm}, nil
+}
+
+// onCollision has the signature of Game.OnCollision and plays the sound
+// with a volume proportional to the impact speed.
+func (s *bounceSound) onCollision(_ *Ball, _ int, impactSpeed float64) {
+	if impactSpeed < soundMinSpeed {
+		return
+	}
+	now := time.Now()
+	if now.Sub(s.lastPlay) < soundMinInterval {
+		return
+	}
+	s.lastPlay = now
+	p := s.ctx.NewPlayerFromBytes(s.pcm)
+	p.SetVolume(math.Min(1, impactSpeed/soundFullSpeed))
+	p.Play()
+}
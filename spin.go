@@ -0,0 +1,48 @@
+package main
+
+import "math"
+
+// ----------------------------------------------------
+// Varying spin.
+// ----------------------------------------------------
+
+// Spin modes: the container turns at a constant speed, or its speed
+// swings sinusoidally between ±base, or it flips between ±base, over each
+// spin period.
+const (
+	spinConstant = "constant"
+	spinSine     = "sine"
+	spinFlip     = "flip"
+)
+
+// spinModes lists the accepted -spin-mode values.
+var spinModes = []string{spinConstant, spinSine, spinFlip}
+
+// updateSpin advances the spin phase by dt seconds and sets the
+// container's angular speed for it, from the base speed the controls set.
+func (g *Game) updateSpin(dt float64) {
+	if g.cfg.SpinMode == spinConstant {
+		return
+	}
+	g.spinPhase += dt / g.cfg.SpinPeriod
+	switch g.cfg.SpinMode {
+	case spinSine:
+		g.world.AngularSpeed = g.spinBase * math.Sin(2*math.Pi*g.spinPhase)
+	case spinFlip:
+		// Forward for the first period, reversed for the next, and so on.
+		if int(g.spinPhase)%2 == 0 {
+			g.world.AngularSpeed = g.spinBase
+		} else {
+			g.world.AngularSpeed = -g.spinBase
+		}
+	}
+}
+
+// baseAngularSpeed returns the angular speed the controls adjust: the
+// base speed while the spin varies, the actual speed otherwise.
+func (g *Game) baseAngularSpeed() float64 {
+	if g.cfg.SpinMode != spinConstant {
+		return g.spinBase
+	}
+	return g.world.AngularSpeed
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// TestSpinModes checks the container's speed over a varying spin's cycle:
+// the sine peaks a quarter period in, and the flip reverses after each
+// period.
+func TestSpinModes(t *testing.T) {
+	tests := []struct {
+		mode string
+		at   float64 // Seconds into the run, with a 4 s period.
+		want float64
+	}{
+		{spinSine, 1, 2},
+		{spinSine, 2, 0},
+		{spinSine, 3, -2},
+		{spinFlip, 1, 2},
+		{spinFlip, 5, -2},
+		{spinFlip, 9, 2},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		cfg.SpinMode, cfg.SpinPeriod, cfg.AngularSpeed = tt.mode, 4, 2
+		g, err := NewGame(cfg, themes[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Advance(tt.at)
+		if got := g.world.AngularSpeed; math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("%s spin at %gs: speed %g, want %g", tt.mode, tt.at, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"michelo851a1203/hex-motion/physics"
+)
+
+// ----------------------------------------------------
+// Squash and stretch.
+// ----------------------------------------------------
+
+const (
+	maxSquash   = 0.35   // Largest squash, as a fraction of the ball's size.
+	squashSpeed = 1500.0 // Impact speed (px/s) that squashes the ball fully.
+	squashTime  = 0.12   // Seconds the ball takes to relax back to round.
+)
+
+// squash is a ball's deformation after a hit: it is flattened along the
+// wall normal by amount (and stretched across it by as much), relaxing
+// linearly to round over squashTime.
+type squash struct {
+	normal Vector
+	amount float64
+}
+
+// squashBall deforms the ball in collision c, harder hits more. A softer
+// hit doesn't interrupt a deeper squash still relaxing.
+func (g *Game) squashBall(c physics.Collision) {
+	amount := maxSquash * math.Min(1, c.ImpactSpeed/squashSpeed)
+	if s, ok := g.squashes[c.Ball]; ok && s.amount >= amount {
+		return
+	}
+	g.squashes[c.Ball] = squash{normal: c.Normal, amount: amount}
+}
+
+// updateSquashes relaxes the deformed balls toward round.
+func (g *Game) updateSquashes(dt float64) {
+	for b, s := range g.squashes {
+		s.amount -= maxSquash * dt / squashTime
+		if s.amount <= 0 {
+			delete(g.squashes, b)
+			continue
+		}
+		g.squashes[b] = s
+	}
+}
+
+// squashGeoM returns the transform deforming ball b's images, which are
+// centered on the origin. It is the identity for a round ball.
+func (g *Game) squashGeoM(b *Ball) ebiten.GeoM {
+	var m ebiten.GeoM
+	s, ok := g.squashes[b]
+	if !ok {
+		return m
+	}
+	angle := math.Atan2(s.normal.Y, s.normal.X)
+	m.Rotate(-angle)
+	m.Scale(1-s.amount, 1+s.amount)
+	m.Rotate(angle)
+	return m
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+
+	"michelo851a1203/hex-motion/physics"
+)
+
+// ----------------------------------------------------
+// Saving and loading simulation state.
+// ----------------------------------------------------
+
+// stateFile is where the save/load keys keep the snapshot.
+const stateFile = "state.json"
+
+// gameState is the JSON snapshot of a Game.
+type gameState struct {
+	Balls           []ballState `json:"balls"`
+	BallRadius      float64     `json:"ballRadius"`
+	HexRotation     float64     `json:"hexRotation"`
+	HexAngularSpeed float64     `json:"hexAngularSpeed"`
+	SpinBase        float64     `json:"spinBase"`  // Speed a varying spin swings around.
+	SpinPhase       float64     `json:"spinPhase"` // Spin periods it has run.
+	Rings           []ringState `json:"rings,omitempty"`
+	Gravity         float64     `json:"gravity"`
+	GravityDir      Vector      `json:"gravityDir"`
+	Restitution     float64     `json:"restitution"`
+	Elapsed         float64     `json:"elapsed"`    // Simulated seconds since the last reset.
+	Collisions      int         `json:"collisions"` // Distinct wall collisions since then.
+}
+
+// ballState is the snapshot of one ball's motion.
+type ballState struct {
+	Pos        Vector  `json:"pos"`
+	Vel        Vector  `json:"vel"`
+	Angle      float64 `json:"angle"`
+	AngularVel float64 `json:"angularVel"`
+	Mass       float64 `json:"mass,omitempty"` // 1 if left out.
+}
+
+// ringState is the snapshot of one nested ring's rotation.
+type ringState struct {
+	Rotation     float64 `json:"rotation"`
+	AngularSpeed float64 `json:"angularSpeed"`
+}
+
+// MarshalState serializes the balls, the hexagon and its spin, the rings
+// and the main physics parameters as JSON.
+func (g *Game) MarshalState() ([]byte, error) {
+	balls := make([]ballState, len(g.world.Balls))
+	for i, b := range g.world.Balls {
+		balls[i] = ballState{Pos: b.Pos, Vel: b.Vel, Angle: b.Angle, AngularVel: b.AngularVel, Mass: b.Mass}
+	}
+	var rings []ringState
+	for _, r := range g.world.Rings {
+		rings = append(rings, ringState{Rotation: r.Rotation, AngularSpeed: r.AngularSpeed})
+	}
+	return json.MarshalIndent(gameState{
+		Balls:           balls,
+		BallRadius:      g.world.BallRadius,
+		HexRotation:     g.world.Rotation,
+		HexAngularSpeed: g.world.AngularSpeed,
+		SpinBase:        g.spinBase,
+		SpinPhase:       g.spinPhase,
+		Rings:           rings,
+		Gravity:         g.world.Gravity,
+		GravityDir:      g.world.GravityDir,
+		Restitution:     g.world.Restitution,
+		Elapsed:         g.simTime,
+		Collisions:      g.collisions,
+	}, "", "  ")
+}
+
+// LoadState restores a snapshot produced by MarshalState. The game is
+// left unchanged if the data is invalid.
+func (g *Game) LoadState(data []byte) error {
+	var s gameState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s.BallRadius <= 0 {
+		return errors.New("state: ball radius must be positive")
+	}
+	if len(s.Balls) == 0 {
+		return errors.New("state: no balls")
+	}
+	if !validRestitution(s.Restitution) {
+		return errors.New("state: restitution must be between 0 and 1")
+	}
+	if len(s.Rings) != len(g.world.Rings) {
+		return fmt.Errorf("state: has %d rings, want %d", len(s.Rings), len(g.world.Rings))
+	}
+	balls := make([]*Ball, len(s.Balls))
+	for i, bs := range s.Balls {
+		if bs.Mass == 0 {
+			bs.Mass = 1
+		}
+		b, err := physics.NewBall(bs.Pos, bs.Vel, bs.Mass, g.cfg.TrailLength)
+		if err != nil {
+			return fmt.Errorf("state: ball %d: %w", i, err)
+		}
+		b.Angle, b.AngularVel = bs.Angle, bs.AngularVel
+		balls[i] = b
+	}
+	g.world.Balls = balls
+	clear(g.squashes)
+	g.contacts = g.contacts[:0]
+	g.dropSnapshot()
+	g.colorBalls()
+	g.world.Rotation = s.HexRotation
+	g.world.AngularSpeed = s.HexAngularSpeed
+	g.spinBase, g.spinPhase = s.SpinBase, s.SpinPhase
+	for k, r := range g.world.Rings {
+		r.Rotation, r.AngularSpeed = s.Rings[k].Rotation, s.Rings[k].AngularSpeed
+	}
+	g.setGravity(s.Gravity)
+	if dir := s.GravityDir.Normalize(); dir != (Vector{}) {
+		g.world.GravityDir = dir
+	}
+	g.world.Restitution = s.Restitution
+	g.simTime, g.collisions = s.Elapsed, s.Collisions
+	// Scripted events before the restored time have already happened.
+	g.scriptNext = 0
+	for g.scriptNext < len(g.script) && g.script[g.scriptNext].At <= g.simTime {
+		g.scriptNext++
+	}
+	if s.BallRadius != g.world.BallRadius {
+		g.world.BallRadius = s.BallRadius
+		g.world.CollisionRadius = math.Max(0, s.BallRadius+g.cfg.CollisionMargin)
+	}
+	return nil
+}
+
+// saveStateFile writes the current state to stateFile.
+func (g *Game) saveStateFile() {
+	data, err := g.MarshalState()
+	if err == nil {
+		err = os.WriteFile(stateFile, data, 0o644)
+	}
+	if err != nil {
+		log.Printf("saving %s: %v", stateFile, err)
+		return
+	}
+	log.Printf("saved %s", stateFile)
+}
+
+// loadStateFile restores the state saved in stateFile.
+func (g *Game) loadStateFile() {
+	data, err := os.ReadFile(stateFile)
+	if err == nil {
+		err = g.LoadState(data)
+	}
+	if err != nil {
+		log.Printf("loading %s: %v", stateFile, err)
+		return
+	}
+	log.Printf("loaded %s", stateFile)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestStateRoundTrip saves a game with a varying spin partway through a
+// run and loads it into a fresh one: saving that gives back the same
+// snapshot, and both games then carry on turning alike.
+func TestStateRoundTrip(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SpinMode, cfg.AngularSpeed = spinSine, 2
+	newGame := func() *Game {
+		g, err := NewGame(cfg, themes[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g
+	}
+	g := newGame()
+	g.Advance(1.3)
+	data, err := g.MarshalState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := newGame()
+	if err := loaded.LoadState(data); err != nil {
+		t.Fatal(err)
+	}
+	again, err := loaded.MarshalState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Errorf("state changed across a round trip:\n%s\nbecame\n%s", data, again)
+	}
+	if loaded.spinBase != 2 {
+		t.Errorf("spin base %g after loading, want 2", loaded.spinBase)
+	}
+
+	g.Advance(0.7)
+	loaded.Advance(0.7)
+	if loaded.world.AngularSpeed != g.world.AngularSpeed || loaded.world.Rotation != g.world.Rotation {
+		t.Errorf("loaded game spins at %g, turned to %g; the original at %g, turned to %g",
+			loaded.world.AngularSpeed, loaded.world.Rotation, g.world.AngularSpeed, g.world.Rotation)
+	}
+}
+
+func TestLoadStateRejects(t *testing.T) {
+	g := newTestGame(t)
+	restitution, balls := g.world.Restitution, len(g.world.Balls)
+	for _, data := range []string{
+		`not json`,
+		`{"ballRadius": 0, "balls": [{}]}`,
+		`{"ballRadius": 10, "balls": []}`,
+		`{"ballRadius": 10, "balls": [{"mass": -1}]}`,
+		`{"ballRadius": 10, "balls": [{}], "restitution": 1.5}`,
+		`{"ballRadius": 10, "balls": [{}], "restitution": -0.2}`,
+	} {
+		if err := g.LoadState([]byte(data)); err == nil {
+			t.Errorf("LoadState accepted %s", data)
+		}
+	}
+	if g.world.Restitution != restitution || len(g.world.Balls) != balls {
+		t.Error("a rejected state changed the game")
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+)
+
+// ----------------------------------------------------
+// Run statistics.
+// ----------------------------------------------------
+
+// checkEnergy counts and logs a bounce that raised ball b's energy from
+// before to after; it serves as the World's OnEnergyGain hook.
+func (g *Game) checkEnergy(b *Ball, before, after float64) {
+	g.energyGains++
+	g.lastEnergyGain = after - before
+	log.Printf("energy check: bounce at t=%.2fs raised energy from %.1f to %.1f", g.simTime, before, after)
+}
+
+// printStats writes a summary of the run to w.
+func (g *Game) printStats(w io.Writer) {
+	s := &g.world.Stats
+	avg := 0.0
+	if s.Bounces > 0 {
+		avg = s.ImpactSum / float64(s.Bounces)
+	}
+	fmt.Fprintf(w, "Run summary (%.1f s simulated)\n", g.simTime)
+	fmt.Fprintf(w, "  bounces:        %d\n", s.Bounces)
+	for i, n := range s.EdgeBounces {
+		fmt.Fprintf(w, "    edge %d:       %d\n", i, n)
+	}
+	fmt.Fprintf(w, "  collisions:     %d distinct\n", g.collisions)
+	fmt.Fprintf(w, "  impact speed:   avg %.1f, max %.1f px/s\n", avg, s.ImpactMax)
+	fmt.Fprintf(w, "  distance:       %.1f px\n", s.Distance)
+	fmt.Fprintf(w, "  final energy:   %.1f\n", g.world.TotalEnergy())
+}
@@ -0,0 +1,88 @@
+package main
+
+import "image/color"
+
+// ----------------------------------------------------
+// Color themes.
+// ----------------------------------------------------
+
+// Theme is a color scheme for the scene.
+type Theme struct {
+	Name       string
+	Background color.RGBA
+	Wall       color.RGBA
+	Grid       color.RGBA   // Faint background grid lines.
+	Rings      []color.RGBA // Colors of the nested rings, outermost first.
+	Balls      []color.RGBA // Palette the balls take their colors from.
+}
+
+// themes lists the built-in themes in the order T cycles through them.
+var themes = []Theme{
+	{
+		Name:       "dark",
+		Background: color.RGBA{30, 30, 30, 255},
+		Wall:       color.RGBA{255, 255, 255, 255},
+		Grid:       color.RGBA{50, 50, 50, 255},
+		Rings: []color.RGBA{
+			{150, 200, 255, 255},
+			{255, 190, 120, 255},
+			{170, 255, 170, 255},
+		},
+		Balls: []color.RGBA{
+			{255, 0, 0, 255},
+			{60, 200, 255, 255},
+			{120, 230, 90, 255},
+			{255, 200, 40, 255},
+			{220, 110, 255, 255},
+			{255, 140, 60, 255},
+		},
+	},
+	{
+		Name:       "light",
+		Background: color.RGBA{240, 238, 230, 255},
+		Wall:       color.RGBA{40, 40, 48, 255},
+		Grid:       color.RGBA{220, 218, 210, 255},
+		Rings: []color.RGBA{
+			{40, 90, 170, 255},
+			{170, 90, 20, 255},
+			{30, 120, 50, 255},
+		},
+		Balls: []color.RGBA{
+			{210, 30, 30, 255},
+			{20, 100, 200, 255},
+			{30, 140, 60, 255},
+			{200, 120, 0, 255},
+			{130, 50, 170, 255},
+			{0, 140, 140, 255},
+		},
+	},
+}
+
+// themeByName looks up a built-in theme.
+func themeByName(name string) (Theme, bool) {
+	for _, t := range themes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// themeNames returns the names of the built-in themes.
+func themeNames() []string {
+	names := make([]string, len(themes))
+	for i, t := range themes {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// nextTheme returns the theme after the one named name, wrapping around.
+func nextTheme(name string) Theme {
+	for i, t := range themes {
+		if t.Name == name {
+			return themes[(i+1)%len(themes)]
+		}
+	}
+	return themes[0]
+}
@@ -0,0 +1,33 @@
+package main
+
+import "math"
+
+// ----------------------------------------------------
+// Gravity well.
+// ----------------------------------------------------
+
+// The well pulls every ball toward the container's center with
+// wellStrength / d² px/s² at distance d, softened over wellSoftening
+// pixels so it stays finite at the center. Like gravity it doesn't
+// depend on the ball's mass.
+const wellSoftening = 40.0
+
+// wellAccel returns the gravity well's acceleration at pos.
+func (g *Game) wellAccel(pos Vector) Vector {
+	offset := g.world.Container.Center().Sub(pos)
+	d2 := offset.Dot(offset) + wellSoftening*wellSoftening
+	return offset.Mul(g.wellStrength / (d2 * math.Sqrt(d2)))
+}
+
+// forceField is the World's Field hook: the breeze plus, when enabled,
+// the gravity well.
+func (g *Game) forceField(pos Vector, t float64) Vector {
+	var accel Vector
+	if g.wind != nil {
+		accel = g.wind(pos, t)
+	}
+	if g.enableGravityWell {
+		accel = accel.Add(g.wellAccel(pos))
+	}
+	return accel
+}
@@ -0,0 +1,24 @@
+package main
+
+import "math"
+
+// ----------------------------------------------------
+// Wind.
+// ----------------------------------------------------
+
+// The breeze blows horizontally, swinging back and forth every
+// windPeriod seconds, with gusts travelling down the screen every
+// windWavelength pixels.
+const (
+	windPeriod     = 4.0
+	windWavelength = 300.0
+)
+
+// breeze returns a force field of a gentle sinusoidal horizontal wind
+// with the given peak acceleration (px/s²).
+func breeze(strength float64) func(pos Vector, t float64) Vector {
+	return func(pos Vector, t float64) Vector {
+		phase := 2*math.Pi*t/windPeriod + 2*math.Pi*pos.Y/windWavelength
+		return Vector{X: strength * math.Sin(phase)}
+	}
+}